@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	formerfast "github.com/martcl/nrk-former/pkg/former-fast"
+)
+
+// batchResult is written to one file per day by runBatch
+type batchResult struct {
+	Day        string       `json:"day"`        // YYYY-MM-DD in Norway
+	DateString string       `json:"dateString"` // the date the seed is made from
+	Seed       string       `json:"seed"`
+	Board      []string     `json:"board"` // one row per string, O, G, P and B for the colors
+	Solution   []batchClick `json:"solution"`
+	Length     int          `json:"length"`   // 0 if no solution was found
+	Complete   bool         `json:"complete"` // false if the search hit the timeout
+	Elapsed    string       `json:"elapsed"`
+	Threads    int          `json:"threads"`
+	Stats      batchStats   `json:"stats"`
+}
+
+// the search stats of formerfast.WorkerStats, summed over the workers
+type batchStats struct {
+	Expanded   uint64 `json:"expanded"`
+	Received   uint64 `json:"received"`
+	Duplicates uint64 `json:"duplicates"`
+	Sent       uint64 `json:"sent"`
+	MaxOpen    int    `json:"maxOpen"` // the largest open list of any worker
+}
+
+type batchClick struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// runBatch solves the boards of a range of days ahead of time, and writes
+// the result of each day to its own file. Several boards are solved at once,
+// and they share the threads so no more than -threads are used in total.
+func runBatch(args []string) {
+	flags := flag.NewFlagSet("batch", flag.ExitOnError)
+	from := flags.String("from", "", "first day to solve, as YYYY-MM-DD, tomorrow if empty")
+	days := flags.Int("days", 7, "number of days to solve")
+	out := flags.String("out", "solutions", "folder to write the results to, one DD-MM-YYYY.json per day")
+	numThreads := flags.Int("threads", 12, "threads used in total")
+	jobs := flags.Int("jobs", 2, "boards solved at once, they share the threads")
+	heuristicTuning := flags.Float64("tuning", 3.4, "distance tuning variable for A*")
+	timeout := flags.Duration("timeout", time.Minute, "time each board gets, 0 means no limit")
+	flags.Parse(args)
+
+	start := formerfast.TodaysBoard(nil).Day.AddDate(0, 0, 1)
+	if *from != "" {
+		day, err := time.Parse("2006-01-02", *from)
+		if err != nil {
+			fmt.Printf("[error] -from: %v\n", err)
+			os.Exit(1)
+		}
+		// noon, so the day is the same in Norway
+		start = day.Add(12 * time.Hour)
+	}
+
+	if *jobs < 1 {
+		*jobs = 1
+	}
+	if *jobs > *numThreads {
+		*jobs = *numThreads
+	}
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		fmt.Printf("[error] %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("[info] Solving %d days, %d at once\n", *days, *jobs)
+	fmt.Printf("[info] Number of threads: %d\n", *numThreads)
+
+	dates := make(chan time.Time)
+	var wg sync.WaitGroup
+	for job := 0; job < *jobs; job++ {
+		// spread the threads over the jobs, the first ones get the remainder
+		threads := *numThreads / *jobs
+		if job < *numThreads%*jobs {
+			threads++
+		}
+
+		wg.Add(1)
+		go func(threads int) {
+			defer wg.Done()
+			for date := range dates {
+				daily := formerfast.DailyBoard(date)
+				result := solveDay(daily, threads, float32(*heuristicTuning), *timeout)
+
+				file := filepath.Join(*out, daily.Day.Format("02-01-2006")+".json")
+				if err := writeBatchResult(file, result); err != nil {
+					fmt.Printf("[error] %v\n", err)
+					continue
+				}
+				fmt.Printf("%s length %-3d complete %-5v %s -> %s\n",
+					result.Day, result.Length, result.Complete, result.Elapsed, file)
+			}
+		}(threads)
+	}
+
+	for i := 0; i < *days; i++ {
+		dates <- start.AddDate(0, 0, i)
+	}
+	close(dates)
+	wg.Wait()
+}
+
+func solveDay(daily *formerfast.Daily, threads int, heuristicTuning float32, timeout time.Duration) batchResult {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	solved := formerfast.Solve(ctx, daily.Board, formerfast.SolveOptions{
+		MaxThreads:      threads,
+		HeuristicTuning: heuristicTuning,
+	})
+
+	result := batchResult{
+		Day:        daily.Day.Format("2006-01-02"),
+		DateString: daily.DateString,
+		Seed:       daily.Seed,
+		Board:      boardRows(daily.Board),
+		Solution:   []batchClick{},
+		Length:     len(solved.Moves),
+		Complete:   solved.Complete,
+		Elapsed:    time.Since(started).Round(time.Millisecond).String(),
+		Threads:    threads,
+	}
+	for _, pos := range solved.Moves {
+		x, y := daily.Board.XY(pos)
+		result.Solution = append(result.Solution, batchClick{X: x, Y: y})
+	}
+	for _, w := range solved.Stats {
+		result.Stats.Expanded += w.Expanded
+		result.Stats.Received += w.Received
+		result.Stats.Duplicates += w.Duplicates
+		result.Stats.Sent += w.Sent
+		result.Stats.MaxOpen = max(result.Stats.MaxOpen, w.MaxOpen)
+	}
+	return result
+}
+
+var brickLetters = map[formerfast.BrickType]string{
+	formerfast.Orange: "O",
+	formerfast.Green:  "G",
+	formerfast.Pink:   "P",
+	formerfast.Blue:   "B",
+}
+
+// boardRows writes the board as one string per row, with # for empty cells
+func boardRows(board *formerfast.Board) []string {
+	rows := make([]string, board.Height())
+	for y := range rows {
+		var row strings.Builder
+		for x := 0; x < board.Width(); x++ {
+			brick, err := board.GetBrick(board.Pos(x, y))
+			if err != nil {
+				row.WriteString("#")
+				continue
+			}
+			row.WriteString(brickLetters[brick])
+		}
+		rows[y] = row.String()
+	}
+	return rows
+}
+
+func writeBatchResult(file string, result batchResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0644)
+}
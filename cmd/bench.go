@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	formerfast "github.com/martcl/nrk-former/pkg/former-fast"
+)
+
+// runBench compares the open lists on every board in the tests folder. It
+// prints how fast each open list pushes and pops the same states, how fast
+// clicks are applied to them, and how many states per second a full search
+// expands with each open list.
+func runBench(args []string) {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	boards := flags.String("boards", "tests/*.json", "glob of board files to solve")
+	heuristicTuning := flags.Float64("tuning", 4.5, "distance tuning variable")
+	numThreads := flags.Int("threads", 1, "number of threads")
+	timeout := flags.Duration("timeout", 30*time.Second, "stop each search after this long")
+	flags.Parse(args)
+
+	files, err := filepath.Glob(*boards)
+	if err != nil || len(files) == 0 {
+		fmt.Printf("[error] No boards found matching %s\n", *boards)
+		os.Exit(1)
+	}
+
+	openLists := []struct {
+		name string
+		new  func() formerfast.OpenList
+	}{
+		{"heap", formerfast.NewHeapOpenList},
+		{"bucket", func() formerfast.OpenList { return formerfast.NewBucketOpenList(4) }},
+	}
+
+	fmt.Printf("[info] Distance tuning variable: %f\n", *heuristicTuning)
+	fmt.Printf("[info] Number of threads: %d\n", *numThreads)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Printf("[error] %v\n", err)
+			os.Exit(1)
+		}
+		board, err := formerfast.LoadBoard(string(data))
+		if err != nil {
+			fmt.Printf("[error] %s: %v\n", file, err)
+			os.Exit(1)
+		}
+
+		// push and pop the same states through each open list on its own,
+		// since expanding states dominates the time of a full search
+		states := formerfast.CollectStates(board, 200000, formerfast.LogHeuristic(float32(*heuristicTuning)))
+		for _, openList := range openLists {
+			start := time.Now()
+			list := openList.new()
+			for _, state := range states {
+				list.Push(state)
+			}
+			for list.Len() > 0 {
+				list.Pop()
+			}
+			elapsed := time.Since(start)
+
+			fmt.Printf("%-20s %-6s queue only, %d states %10.0f push+pop/s\n",
+				filepath.Base(file), openList.name, len(states), float64(len(states))/elapsed.Seconds())
+		}
+
+		// apply every possible click to the same states, to time
+		// removing groups and letting the bricks fall
+		start := time.Now()
+		clicks := 0
+		for _, state := range states {
+			for _, group := range state.Board.GetPossibleGroups() {
+				nextBoard := *state.Board
+				nextBoard.RemoveGroup(group)
+				nextBoard.Gravity()
+				clicks++
+			}
+		}
+		elapsed := time.Since(start)
+		fmt.Printf("%-20s clicks only, %d clicks %10.0f clicks/s\n",
+			filepath.Base(file), clicks, float64(clicks)/elapsed.Seconds())
+
+		for _, openList := range openLists {
+			ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+			start := time.Now()
+			result := formerfast.Solve(ctx, board, formerfast.SolveOptions{
+				MaxThreads:      *numThreads,
+				HeuristicTuning: float32(*heuristicTuning),
+				NewOpenList:     openList.new,
+			})
+			elapsed := time.Since(start)
+			cancel()
+
+			expanded := uint64(0)
+			for _, w := range result.Stats {
+				expanded += w.Expanded
+			}
+
+			length := "-"
+			if result.Moves != nil {
+				length = fmt.Sprint(len(result.Moves))
+			}
+			fmt.Printf("%-20s %-6s length %-3s expanded %-9d %10.0f states/s %v\n",
+				filepath.Base(file), openList.name, length, expanded,
+				float64(expanded)/elapsed.Seconds(), elapsed.Round(time.Millisecond))
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	formerfast "github.com/martcl/nrk-former/pkg/former-fast"
+)
+
+// date formats tried by calibrate, as Go layouts. Only the year, month and day
+// parts of a layout are used, see formerfast.FormatDate.
+var calibrateFormats = []string{
+	"02012006", "01022006", "20060102", "20060201",
+	"02-01-2006", "01-02-2006", "2006-01-02", "2006-02-01",
+	"02.01.2006", "02/01/2006", "01/02/2006",
+	"2.1.2006", "1/2/2006", "2006-1-2",
+}
+
+// a way of turning a date into a board
+type dateMapping struct {
+	offset    int    // days added to the date of the board
+	format    string // Go layout of the date string
+	zeroMonth bool   // months are counted from 0, like getMonth in JavaScript
+	zone      string // time zone the date is read in
+	md5       bool   // the seed is the md5 of the date string, not the string itself
+}
+
+func (m dateMapping) String() string {
+	month := ""
+	if m.zeroMonth {
+		month = " with months from 0"
+	}
+	seed := "plain"
+	if m.md5 {
+		seed = "md5"
+	}
+	return fmt.Sprintf("offset %d, format %q%s, zone %s, seed %s", m.offset, m.format, month, m.zone, seed)
+}
+
+// runCalibrate tries many ways of turning the date of each board in the
+// tests folder into a seed, and reports the ones that make the same board.
+// The boards are named after the day they were played, like 25-11-2024.json.
+//
+// The game shows the same board all day in Norway, so a mapping only counts
+// if it makes the board at every one of -hours. Around midnight the date in
+// Norway and in UTC differ, which tells the time zones apart.
+func runCalibrate(args []string) {
+	flags := flag.NewFlagSet("calibrate", flag.ExitOnError)
+	boards := flags.String("boards", "tests/*.json", "glob of board files named DD-MM-YYYY.json")
+	minOffset := flags.Int("min-offset", -400, "lowest day offset to try")
+	maxOffset := flags.Int("max-offset", 400, "highest day offset to try")
+	formats := flags.String("formats", strings.Join(calibrateFormats, ","), "comma separated Go layouts of the date string")
+	zones := flags.String("zones", "Europe/Oslo,UTC", "comma separated time zones the game may read the date in")
+	hours := flags.String("hours", "0,1,12,23", "comma separated hours of the day in Norway the board was played")
+	flags.Parse(args)
+
+	playedHours := []int{}
+	for _, field := range strings.Split(*hours, ",") {
+		hour, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || hour < 0 || hour > 23 {
+			fmt.Printf("[error] -hours: %q is not an hour\n", field)
+			os.Exit(2)
+		}
+		playedHours = append(playedHours, hour)
+	}
+
+	files, err := filepath.Glob(*boards)
+	if err != nil || len(files) == 0 {
+		fmt.Printf("[error] No boards found matching %s\n", *boards)
+		os.Exit(1)
+	}
+
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		fmt.Printf("[error] %v\n", err)
+		os.Exit(1)
+	}
+	locations := []*time.Location{}
+	for _, zone := range strings.Split(*zones, ",") {
+		location, err := time.LoadLocation(zone)
+		if err != nil {
+			fmt.Printf("[error] %v\n", err)
+			os.Exit(1)
+		}
+		locations = append(locations, location)
+	}
+
+	// how many boards each mapping reproduced
+	matches := map[dateMapping]int{}
+	calibrated := 0
+
+	for _, file := range files {
+		day, err := time.ParseInLocation("02-01-2006", strings.TrimSuffix(filepath.Base(file), ".json"), oslo)
+		if err != nil {
+			fmt.Printf("[error] %s is not named after a date: %v\n", file, err)
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Printf("[error] %v\n", err)
+			continue
+		}
+		want, err := formerfast.LoadBoard(string(data))
+		if err != nil {
+			fmt.Printf("[error] %s: %v\n", file, err)
+			continue
+		}
+		calibrated++
+
+		// the hours each mapping made the board at, and its date string
+		hoursFound := map[dateMapping]int{}
+		dateStrings := map[dateMapping]string{}
+		for _, hour := range playedHours {
+			played := time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, oslo)
+			for _, location := range locations {
+				for offset := *minOffset; offset <= *maxOffset; offset++ {
+					date := played.In(location).AddDate(0, 0, offset)
+					for _, format := range strings.Split(*formats, ",") {
+						for _, zeroMonth := range []bool{false, true} {
+							dateString := formerfast.FormatDate(date, format, zeroMonth)
+							for _, useMD5 := range []bool{true, false} {
+								seed := dateString
+								if useMD5 {
+									seed = formerfast.MD5Seed(dateString)
+								}
+
+								board, err := formerfast.CreateBoardWithPseudoRandom(want.Height(), want.Width(), formerfast.InitializeRandomState(seed))
+								if err != nil || !board.Equal(want) {
+									continue
+								}
+
+								mapping := dateMapping{offset, format, zeroMonth, location.String(), useMD5}
+								hoursFound[mapping]++
+								dateStrings[mapping] = dateString
+							}
+						}
+					}
+				}
+			}
+		}
+
+		found := []dateMapping{}
+		for mapping, count := range hoursFound {
+			if count == len(playedHours) {
+				found = append(found, mapping)
+			}
+		}
+		sort.Slice(found, func(i, j int) bool {
+			return found[i].String() < found[j].String()
+		})
+		for _, mapping := range found {
+			matches[mapping]++
+			fmt.Printf("%-20s %s, date string %q\n", filepath.Base(file), mapping, dateStrings[mapping])
+		}
+		if len(found) == 0 {
+			fmt.Printf("%-20s no mapping found\n", filepath.Base(file))
+		}
+	}
+
+	// the mappings that reproduced every board
+	everywhere := []string{}
+	for mapping, count := range matches {
+		if count == calibrated {
+			everywhere = append(everywhere, mapping.String())
+		}
+	}
+	sort.Strings(everywhere)
+
+	fmt.Println()
+	if len(everywhere) == 0 {
+		fmt.Println("[info] No mapping reproduces every board")
+		return
+	}
+	for _, mapping := range everywhere {
+		fmt.Printf("[info] Reproduces all %d boards: %s\n", calibrated, mapping)
+	}
+}
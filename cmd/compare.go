@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	formerfast "github.com/martcl/nrk-former/pkg/former-fast"
+)
+
+// runCompare solves every board in the tests folder with each solver, and
+// prints the length of the solution each one found and how long it took
+func runCompare(args []string) {
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	boards := flags.String("boards", "tests/*.json", "glob of board files to solve")
+	heuristicTuning := flags.Float64("tuning", 4.5, "distance tuning variable for A*")
+	beamWidth := flags.Int("beam", 100, "states kept per click by beam search")
+	budget := flags.Duration("budget", 10*time.Second, "time each solver gets per board")
+	numThreads := flags.Int("threads", 1, "number of threads")
+	flags.Parse(args)
+
+	files, err := filepath.Glob(*boards)
+	if err != nil || len(files) == 0 {
+		fmt.Printf("[error] No boards found matching %s\n", *boards)
+		os.Exit(1)
+	}
+
+	solvers := []struct {
+		name  string
+		solve func(ctx context.Context, board *formerfast.Board) []uint8
+	}{
+		{"astar", func(ctx context.Context, board *formerfast.Board) []uint8 {
+			return formerfast.Solve(ctx, board, formerfast.SolveOptions{
+				MaxThreads:      *numThreads,
+				HeuristicTuning: float32(*heuristicTuning),
+			}).Moves
+		}},
+		{"beam", func(ctx context.Context, board *formerfast.Board) []uint8 {
+			return formerfast.SolveBoardUsingBeamSearch(board, formerfast.BeamOptions{
+				Width:      *beamWidth,
+				MaxThreads: *numThreads,
+			})
+		}},
+		{"nrpa", func(ctx context.Context, board *formerfast.Board) []uint8 {
+			return formerfast.SolveBoardUsingNRPA(ctx, board, formerfast.NRPAOptions{
+				MaxThreads: *numThreads,
+			})
+		}},
+	}
+
+	fmt.Printf("[info] Time budget: %s\n", *budget)
+	fmt.Printf("[info] Number of threads: %d\n", *numThreads)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Printf("[error] %v\n", err)
+			os.Exit(1)
+		}
+		board, err := formerfast.LoadBoard(string(data))
+		if err != nil {
+			fmt.Printf("[error] %s: %v\n", file, err)
+			os.Exit(1)
+		}
+
+		for _, solver := range solvers {
+			ctx, cancel := context.WithTimeout(context.Background(), *budget)
+			start := time.Now()
+			solution := solver.solve(ctx, board)
+			elapsed := time.Since(start)
+			cancel()
+
+			length := "-"
+			if solution != nil {
+				length = fmt.Sprint(len(solution))
+			}
+			fmt.Printf("%-20s %-6s length %-3s %v\n",
+				filepath.Base(file), solver.name, length, elapsed.Round(time.Millisecond))
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	formerfast "github.com/martcl/nrk-former/pkg/former-fast"
+)
+
+var colorNames = map[formerfast.BrickType]string{
+	formerfast.Orange: "orange",
+	formerfast.Green:  "green",
+	formerfast.Pink:   "pink",
+	formerfast.Blue:   "blue",
+}
+
+// runHint loads a board that may already be partly played, and prints the
+// best next clicks
+func runHint(args []string) {
+	flags := flag.NewFlagSet("hint", flag.ExitOnError)
+	boardFile := flags.String("board", "", "board JSON file from the game, cleared cells have isEmpty set")
+	count := flags.Int("n", 3, "number of hints")
+	beamWidth := flags.Int("beam", 100, "states kept per click by the beam search after each hint")
+	numThreads := flags.Int("threads", 4, "number of threads")
+	timeout := flags.Duration("timeout", 0, "stop looking after this long, 0 means no limit")
+	flags.Parse(args)
+
+	if *boardFile == "" {
+		fmt.Println("[error] -board is needed")
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*boardFile)
+	if err != nil {
+		fmt.Printf("[error] %v\n", err)
+		os.Exit(1)
+	}
+	board, err := formerfast.LoadBoard(string(data))
+	if err != nil {
+		fmt.Printf("[error] %s: %v\n", *boardFile, err)
+		os.Exit(1)
+	}
+	board.PrintBoard()
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	hints := formerfast.GetHints(ctx, board, formerfast.HintOptions{
+		Count:      *count,
+		BeamWidth:  *beamWidth,
+		MaxThreads: *numThreads,
+	})
+	if len(hints) == 0 {
+		fmt.Println("\nNo hints found")
+		return
+	}
+
+	fmt.Println()
+	for i, hint := range hints {
+		x, y := board.XY(hint.Click)
+		fmt.Printf("hint %d. (x: %d, y:%d) removes %d %s, %d clicks left (at least %d)\n",
+			i+1, x, y, hint.Removed.Size, colorNames[hint.Removed.Color], hint.Clicks, hint.LowerBound)
+	}
+}
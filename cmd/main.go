@@ -1,41 +1,166 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	formerfast "github.com/martcl/nrk-former/pkg/former-fast"
 )
 
+// the anytime search keeps about 260 bytes per state, so this is about 4 GB
+const anytimeMaxStates = 15_000_000
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "bench":
+			runBench(os.Args[2:])
+			return
+		case "compare":
+			runCompare(os.Args[2:])
+			return
+		case "verify":
+			runVerify(os.Args[2:])
+			return
+		case "hint":
+			runHint(os.Args[2:])
+			return
+		case "calibrate":
+			runCalibrate(os.Args[2:])
+			return
+		case "seed":
+			runSeed(os.Args[2:])
+			return
+		case "batch":
+			runBatch(os.Args[2:])
+			return
+		}
+	}
 
-	// Can use the current date to generate the board
-	// but are to unstable since I can't figure out how
-	// to predict the excat date. Better to just use the
-	// seed we are given for now to generate board.
-	// board := formerfast.CreateBoardFromDate(time.Now())
+	optimal := flag.Bool("optimal", false, "find the shortest possible solution, this is a lot slower")
+	anytime := flag.Bool("anytime", false, "print shorter and shorter solutions until the best is proven or the timeout is hit")
+	beamWidth := flag.Int("beam", 0, "use beam search keeping this many states per click, 0 means A*")
+	heuristicName := flag.String("heuristic", "log", "heuristic used by A*, beam search and the anytime search, one of: "+strings.Join(formerfast.HeuristicNames(), ", "))
+	stats := flag.Bool("stats", false, "print how much work each worker did")
+	timeout := flag.Duration("timeout", 0, "stop the search after this long, 0 means no limit")
+	date := flag.String("date", "", "solve the board of this day, as YYYY-MM-DD or today, instead of the built in seed")
+	flag.Parse()
 
-	// using seed to generate the board
-	randomState := formerfast.InitializeRandomState("cff00d616484462eb325f50a5c0cd6a3")
-	board, _ := formerfast.CreateBoardWithPseudoRandom(7, 9, randomState)
+	// using seed to generate the board, unless a date is given
+	var board *formerfast.Board
+	if *date == "" {
+		randomState := formerfast.InitializeRandomState("cff00d616484462eb325f50a5c0cd6a3")
+		board, _ = formerfast.CreateBoardWithPseudoRandom(9, 7, randomState)
+	} else {
+		clock := time.Now
+		if *date != "today" {
+			day, err := time.Parse("2006-01-02", *date)
+			if err != nil {
+				fmt.Printf("[error] -date: %v\n", err)
+				os.Exit(1)
+			}
+			// noon, so the day is the same in Norway
+			clock = func() time.Time { return day.Add(12 * time.Hour) }
+		}
+		daily := formerfast.TodaysBoard(clock)
+		fmt.Printf("[info] Board of %s in Norway, seed %s is md5 of %q\n", daily.Day.Format("2006-01-02"), daily.Seed, daily.DateString)
+		board = daily.Board
+	}
 
 	// better to start high, then make it smaller. high ~ 6, low ~ 3
 	heuristicTuning := 3.4
 	numThreads := 12
 
-	fmt.Printf("[info] Distance tuning variable: %f\n", heuristicTuning)
+	heuristic, err := formerfast.HeuristicByName(*heuristicName, float32(heuristicTuning))
+	if err != nil {
+		fmt.Printf("[error] %v\n", err)
+		os.Exit(1)
+	}
+
+	if !*optimal {
+		fmt.Printf("[info] Heuristic: %s\n", *heuristicName)
+		fmt.Printf("[info] Distance tuning variable: %f\n", heuristicTuning)
+	}
 	fmt.Printf("[info] Number of threads: %d\n", numThreads)
 
 	board.PrintBoard()
 
-	solution := formerfast.SolveBoardUsingAStar(board, numThreads, float32(heuristicTuning))
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	var result formerfast.SolveResult
+	switch {
+	case *optimal:
+		result = formerfast.SolveOptimal(ctx, board, numThreads)
+	case *beamWidth > 0:
+		result = formerfast.SolveBeam(ctx, board, formerfast.BeamOptions{
+			Width:      *beamWidth,
+			Score:      heuristic,
+			MaxThreads: numThreads,
+		})
+	case *anytime:
+		fmt.Println()
+		result = formerfast.SolveAnytime(ctx, board, formerfast.AnytimeOptions{
+			InitialWeight: 2,
+			WeightStep:    0.5,
+			Heuristic:     heuristic,
+			MaxStates:     anytimeMaxStates,
+		}, func(s formerfast.AnytimeSolution) {
+			fmt.Printf("[info] Found solution with length %d using weight %.1f, at most %.2f times the best\n", len(s.Moves), s.Weight, s.Bound)
+		})
+	default:
+		result = formerfast.Solve(ctx, board, formerfast.SolveOptions{
+			MaxThreads:      numThreads,
+			HeuristicTuning: float32(heuristicTuning),
+			Heuristic:       heuristic,
+		})
+	}
+	if !result.Complete && ctx.Err() == nil {
+		fmt.Printf("\n[info] Search stopped after keeping %d states\n", anytimeMaxStates)
+	} else if !result.Complete {
+		fmt.Printf("\n[info] Search stopped after %s\n", *timeout)
+	}
+	solution := result.Moves
+
+	if *stats {
+		for i, w := range result.Stats {
+			fmt.Printf("[info] Worker %d: expanded %d, received %d, duplicates %d, sent %d, max open %d\n",
+				i, w.Expanded, w.Received, w.Duplicates, w.Sent, w.MaxOpen)
+		}
+	}
+
+	if solution == nil {
+		fmt.Println("\nNo solution found")
+		return
+	}
 
 	fmt.Printf("\nFound solution with length: %d\n", len(solution))
+	// the optimal and anytime searches are complete when they have proven
+	// the solution
+	if (*optimal || *anytime) && result.Complete {
+		fmt.Println("[info] Solution is proven minimal, no shorter solution exists")
+	}
 
 	// Apply the solution to verify
+	game := formerfast.NewGame(board)
 	for i, pos := range solution {
-		// board.RemoveBricksIterative(pos)
-		// board.Gravity()
-		fmt.Printf("click %d. (x: %d, y:%d)\n", i, pos%7, (pos / 7))
-		// board.PrintBoard()
+		x, y := board.XY(pos)
+		fmt.Printf("click %d. (x: %d, y:%d)\n", i, x, y)
+		if _, err := game.Click(pos); err != nil {
+			fmt.Printf("[error] %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if !game.IsCleared() {
+		fmt.Println("[error] Solution does not clear the board")
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	formerfast "github.com/martcl/nrk-former/pkg/former-fast"
+)
+
+// runSeed finds the seed that generates a board file, and prints the board
+// the next seed makes
+func runSeed(args []string) {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	boardFile := flags.String("board", "", "board JSON file of a board that has not been played yet")
+	from := flags.String("from", "2024-01-01", "first date to try, as YYYY-MM-DD")
+	to := flags.String("to", "", "last date to try, as YYYY-MM-DD, a year from today if empty")
+	formats := flags.String("formats", "02012006", "comma separated Go layouts of the date string")
+	zeroMonth := flags.Bool("zero-month", true, "also try counting months from 0, like getMonth in JavaScript")
+	seedsFile := flags.String("seeds", "", "file with more seeds to try, one per line")
+	numThreads := flags.Int("threads", 4, "number of threads")
+	timeout := flags.Duration("timeout", 0, "stop looking after this long, 0 means no limit")
+	flags.Parse(args)
+
+	if *boardFile == "" {
+		fmt.Println("[error] -board is needed")
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*boardFile)
+	if err != nil {
+		fmt.Printf("[error] %v\n", err)
+		os.Exit(1)
+	}
+	board, err := formerfast.LoadBoard(string(data))
+	if err != nil {
+		fmt.Printf("[error] %s: %v\n", *boardFile, err)
+		os.Exit(1)
+	}
+
+	opts := formerfast.SeedSearchOptions{
+		Formats:    strings.Split(*formats, ","),
+		ZeroMonth:  *zeroMonth,
+		MaxThreads: *numThreads,
+	}
+	if opts.From, err = time.Parse("2006-01-02", *from); err != nil {
+		fmt.Printf("[error] -from: %v\n", err)
+		os.Exit(1)
+	}
+	opts.To = time.Now().AddDate(1, 0, 0)
+	if *to != "" {
+		if opts.To, err = time.Parse("2006-01-02", *to); err != nil {
+			fmt.Printf("[error] -to: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *seedsFile != "" {
+		if opts.Seeds, err = readLines(*seedsFile); err != nil {
+			fmt.Printf("[error] %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	fmt.Printf("[info] Trying dates from %s to %s\n", opts.From.Format("2006-01-02"), opts.To.Format("2006-01-02"))
+	matches, err := formerfast.FindSeeds(ctx, board, opts)
+	if err != nil {
+		fmt.Printf("[info] Search stopped after %s\n", *timeout)
+	}
+	if len(matches) == 0 {
+		fmt.Println("[info] No seed found")
+		os.Exit(1)
+	}
+
+	for _, match := range matches {
+		if match.DateString == "" {
+			fmt.Printf("[info] Seed %s from the seeds file\n", match.Seed)
+			continue
+		}
+		month := ""
+		if match.ZeroMonth {
+			month = " with months from 0"
+		}
+		fmt.Printf("[info] Seed %s is md5 of %q, the date %s in format %q%s\n",
+			match.Seed, match.DateString, match.Date.Format("2006-01-02"), match.Format, month)
+	}
+
+	// the day after the first date match gives the next board
+	for _, match := range matches {
+		if match.DateString == "" {
+			continue
+		}
+		next := formerfast.FormatDate(match.Date.AddDate(0, 0, 1), match.Format, match.ZeroMonth)
+		nextBoard, err := formerfast.CreateBoardWithPseudoRandom(board.Height(), board.Width(), formerfast.InitializeRandomState(formerfast.MD5Seed(next)))
+		if err != nil {
+			fmt.Printf("[error] %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\n[info] Next board is made from %q\n", next)
+		nextBoard.PrintBoard()
+		break
+	}
+}
+
+// readLines returns the lines in file that are not empty
+func readLines(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
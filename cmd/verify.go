@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/martcl/nrk-former/pkg/former"
+	formerfast "github.com/martcl/nrk-former/pkg/former-fast"
+)
+
+// runVerify plays a solution file on a board file, with both the readable
+// and the fast board, and reports whether the solution clears the board
+func runVerify(args []string) {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	boardFile := flags.String("board", "", "board JSON file, like the ones in tests/")
+	solutionFile := flags.String("solution", "", "file with one click per line, as \"x y\" or the \"click 0. (x: 1, y:7)\" lines printed by the solver")
+	flags.Parse(args)
+
+	if *boardFile == "" || *solutionFile == "" {
+		fmt.Println("[error] Both -board and -solution are needed")
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*boardFile)
+	if err != nil {
+		fmt.Printf("[error] %v\n", err)
+		os.Exit(1)
+	}
+	board, err := former.LoadBoard(string(data))
+	if err != nil {
+		fmt.Printf("[error] %s: %v\n", *boardFile, err)
+		os.Exit(1)
+	}
+	fastBoard, err := formerfast.FromFormerBoard(board)
+	if err != nil {
+		fmt.Printf("[error] %s: %v\n", *boardFile, err)
+		os.Exit(1)
+	}
+
+	clicks, err := readSolution(*solutionFile)
+	if err != nil {
+		fmt.Printf("[error] %v\n", err)
+		os.Exit(1)
+	}
+
+	// clicks outside the board are reported by former.Verify, so the fast
+	// board only plays the clicks before the first one
+	positions := []uint8{}
+	for _, click := range clicks {
+		pos, err := fastBoard.FromClick(click)
+		if err != nil {
+			break
+		}
+		positions = append(positions, pos)
+	}
+
+	result, err := former.Verify(board, clicks)
+	fastResult, fastErr := formerfast.Verify(fastBoard, positions)
+	if result.Played != fastResult.Played || result.Remaining != fastResult.Remaining {
+		fmt.Printf("[error] The boards disagree: former played %d clicks with %d bricks left, formerfast played %d clicks with %d bricks left\n",
+			result.Played, result.Remaining, fastResult.Played, fastResult.Remaining)
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf("[error] %v\n", err)
+		fmt.Printf("[info] Board after %d clicks:\n", result.Played)
+		result.Board.PrintBoard()
+		os.Exit(1)
+	}
+	if fastErr != nil {
+		fmt.Printf("[error] %v\n", fastErr)
+		os.Exit(1)
+	}
+	if !result.Cleared() {
+		fmt.Printf("[error] %d bricks left after %d clicks\n", result.Remaining, result.Played)
+		result.Board.PrintBoard()
+		os.Exit(1)
+	}
+
+	fmt.Printf("[info] Solution clears the board in %d clicks\n", result.Played)
+}
+
+var (
+	clickLine = regexp.MustCompile(`^click \d+\. \(x: *(\d+), *y: *(\d+)\)$`)
+	pairLine  = regexp.MustCompile(`^(-?\d+)[ ,\t]+(-?\d+)$`)
+)
+
+// readSolution reads clicks from a file. Empty lines and lines starting with #
+// are skipped.
+func readSolution(file string) ([]former.Click, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	clicks := []former.Click{}
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		match := clickLine.FindStringSubmatch(line)
+		if match == nil {
+			match = pairLine.FindStringSubmatch(line)
+		}
+		if match == nil {
+			return nil, fmt.Errorf("%s:%d: expected a click, got %q", file, lineNumber, line)
+		}
+
+		x, _ := strconv.Atoi(match[1])
+		y, _ := strconv.Atoi(match[2])
+		clicks = append(clicks, former.Click{X: x, Y: y})
+	}
+	return clicks, scanner.Err()
+}
@@ -0,0 +1,151 @@
+// Package alea is a port of Johannes Baagøe's Alea generator, the one NRK
+// former uses to generate its boards. It gives the same numbers as the
+// JavaScript version, bit for bit, for the same seeds.
+//
+// Numbers in JavaScript are float64, and the generator relies on how
+// JavaScript converts them to 32 bit integers, so the port does all its math
+// in float64 and does those conversions the same way. Every product that is
+// added to something is converted to float64 on its own, which stops the
+// compiler from fusing the two into one operation with a different rounding.
+package alea
+
+import (
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	twoPow32      = 4294967296.0           // 2^32
+	twoPowMinus32 = 2.3283064365386963e-10 // 2^-32
+	twoPowMinus53 = 1.1102230246251565e-16 // 2^-53
+)
+
+// toUint32 converts x the way x >>> 0 does in JavaScript
+func toUint32(x float64) float64 {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return 0
+	}
+	x = math.Mod(math.Trunc(x), twoPow32)
+	if x < 0 {
+		x += twoPow32
+	}
+	return x
+}
+
+// toInt32 converts x the way x | 0 does in JavaScript
+func toInt32(x float64) float64 {
+	x = toUint32(x)
+	if x >= twoPow32/2 {
+		x -= twoPow32
+	}
+	return x
+}
+
+// Mash is the hash Alea uses to turn its seeds into a starting state. Every
+// call to Sum changes the state of the Mash, so the same data gives a
+// different number the next time.
+type Mash struct {
+	n float64
+}
+
+func NewMash() *Mash {
+	return &Mash{n: 0xefc8249d}
+}
+
+// Sum mixes data into the mash and returns a number in [0, 1). Like
+// charCodeAt in JavaScript it works on the UTF-16 code units of data.
+func (m *Mash) Sum(data string) float64 {
+	for _, code := range utf16.Encode([]rune(data)) {
+		m.n += float64(code)
+		h := 0.02519603282416938 * m.n
+		m.n = toUint32(h)
+		h -= m.n
+		h *= m.n
+		m.n = toUint32(h)
+		h -= m.n
+		m.n += float64(h * twoPow32)
+	}
+	return toUint32(m.n) * twoPowMinus32
+}
+
+// Alea is a seeded generator. The zero value is not seeded, use New.
+type Alea struct {
+	s0, s1, s2 float64
+	c          float64
+}
+
+// New seeds a generator with every seed in turn, like Alea(seeds...) in
+// JavaScript. Without seeds it is seeded with the current time in
+// milliseconds, like the JavaScript version.
+func New(seeds ...string) *Alea {
+	if len(seeds) == 0 {
+		seeds = []string{strconv.FormatInt(time.Now().UnixMilli(), 10)}
+	}
+
+	mash := NewMash()
+	a := &Alea{
+		s0: mash.Sum(" "),
+		s1: mash.Sum(" "),
+		s2: mash.Sum(" "),
+		c:  1,
+	}
+	for _, seed := range seeds {
+		a.s0 -= mash.Sum(seed)
+		if a.s0 < 0 {
+			a.s0 += 1
+		}
+		a.s1 -= mash.Sum(seed)
+		if a.s1 < 0 {
+			a.s1 += 1
+		}
+		a.s2 -= mash.Sum(seed)
+		if a.s2 < 0 {
+			a.s2 += 1
+		}
+	}
+	return a
+}
+
+// Next returns the next number in [0, 1), with 32 bits of randomness
+func (a *Alea) Next() float64 {
+	t := float64(2091639*a.s0) + float64(a.c*twoPowMinus32)
+	a.s0 = a.s1
+	a.s1 = a.s2
+	a.c = toInt32(t)
+	a.s2 = t - a.c
+	return a.s2
+}
+
+// Uint32 returns the next number as an integer in [0, 2^32)
+func (a *Alea) Uint32() uint32 {
+	return uint32(a.Next() * twoPow32)
+}
+
+// Fract53 returns the next number in [0, 1), with 53 bits of randomness made
+// from two calls to Next
+func (a *Alea) Fract53() float64 {
+	high := a.Next()
+	low := toInt32(a.Next() * 0x200000)
+	return high + float64(low*twoPowMinus53)
+}
+
+// State is everything a generator needs to continue where it was. It can be
+// stored, as JSON for example, and given to FromState to continue later.
+type State struct {
+	S0 float64 `json:"s0"`
+	S1 float64 `json:"s1"`
+	S2 float64 `json:"s2"`
+	C  float64 `json:"c"`
+}
+
+// State returns the current state of the generator
+func (a *Alea) State() State {
+	return State{S0: a.s0, S1: a.s1, S2: a.s2, C: a.c}
+}
+
+// FromState returns a generator that continues from state
+func FromState(state State) *Alea {
+	return &Alea{s0: state.S0, s1: state.S1, s2: state.S2, c: state.C}
+}
@@ -0,0 +1,77 @@
+package alea
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// the expected numbers come from the JavaScript version of Alea
+
+func TestNext(t *testing.T) {
+	// the example Baagøe publishes with Alea, Alea("my", 3, "seeds")
+	a := New("my", "3", "seeds")
+	for i, want := range []float64{0.30802189325913787, 0.5190450621303171, 0.43635262292809784} {
+		if got := a.Next(); got != want {
+			t.Errorf("number %d: got %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestUint32(t *testing.T) {
+	a := New("")
+	for i, want := range []uint32{715789690, 2091287642, 486307} {
+		if got := a.Uint32(); got != want {
+			t.Errorf("number %d: got %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestFract53(t *testing.T) {
+	a := New("cff00d616484462eb325f50a5c0cd6a3")
+	for i, want := range []float64{0.7655747325122007, 0.7066401443368981, 0.6269537285167656} {
+		if got := a.Fract53(); got != want {
+			t.Errorf("number %d: got %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestSeeds(t *testing.T) {
+	// every seed is mashed on its own, so two seeds are not the same as one
+	// seed with both strings
+	a := New("a", "b")
+	for i, want := range []float64{0.5476640737615526, 0.7266150347422808} {
+		if got := a.Next(); got != want {
+			t.Errorf("New(\"a\", \"b\") number %d: got %v, want %v", i, got, want)
+		}
+	}
+	if got, want := New("ab").Next(), 0.6543413328472525; got != want {
+		t.Errorf("New(\"ab\"): got %v, want %v", got, want)
+	}
+}
+
+func TestState(t *testing.T) {
+	a := New("my", "3", "seeds")
+	a.Next()
+	a.Next()
+
+	want := State{S0: 0.3493568613193929, S1: 0.30802189325913787, S2: 0.5190450621303171, C: 1285867}
+	if got := a.State(); got != want {
+		t.Fatalf("got state %+v, want %+v", got, want)
+	}
+
+	data, err := json.Marshal(a.State())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+
+	b := FromState(state)
+	for i := 0; i < 100; i++ {
+		if got, want := b.Next(), a.Next(); got != want {
+			t.Fatalf("number %d after FromState: got %v, want %v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package formerfast
+
+import (
+	"container/heap"
+	"context"
+	"sync/atomic"
+)
+
+type AnytimeOptions struct {
+	InitialWeight float32 // weight on the estimate in the first search, at least 1
+	WeightStep    float32 // how much the weight drops between searches
+	// orders the open list in the searches with a weight above 1, lowerBound
+	// if nil. Pruning and the reported Bound always use lowerBound.
+	Heuristic Heuristic
+	// the search stops when it keeps this many states, 0 means no limit
+	MaxStates int
+}
+
+// AnytimeSolution is reported every time SolveAnytime finds a shorter solution
+type AnytimeSolution struct {
+	Moves  []uint8
+	Weight float32 // weight used by the search that found it, 0 for the beam search
+	// When it was found, the solution was known to be at most Bound times
+	// longer than the best one. A bound of 1 means it is proven minimal.
+	Bound float32
+}
+
+// SolveAnytime finds a solution quickly and then keeps improving it, in the
+// style of ARA*. The first solution comes from a beam search. Then a search
+// ordered on g + InitialWeight*Heuristic looks for a shorter one, which makes
+// it greedy and fast. Every following search lowers the weight by WeightStep
+// and continues from the states the previous search left behind instead of
+// starting over. The last search, at weight 1, is A* on lowerBound. Each
+// strictly shorter solution is passed to onSolution as soon as it is found,
+// in the middle of a search.
+//
+// The search stops when the solution is proven minimal, in which case the
+// result is Complete, when ctx is cancelled, or when it holds MaxStates
+// states. The result holds the best solution found either way.
+//
+// Every state that can still lead to a shorter solution is kept in memory,
+// like in A*. States that can't are dropped each time a shorter solution is
+// found, but on a hard board the memory use still grows by gigabytes a
+// minute, so give it a timeout or MaxStates.
+func SolveAnytime(ctx context.Context, board *Board, opts AnytimeOptions, onSolution func(AnytimeSolution)) SolveResult {
+	if opts.InitialWeight < 1 {
+		opts.InitialWeight = 1
+	}
+	if opts.WeightStep <= 0 {
+		opts.WeightStep = 0.5
+	}
+
+	s := &araSearch{
+		heuristic:  opts.Heuristic,
+		maxStates:  opts.MaxStates,
+		nodes:      newStateMap[*araNode](),
+		open:       &araOpen{},
+		onSolution: onSolution,
+	}
+	// checked on every expansion, a context is slower to ask
+	stopWatching := context.AfterFunc(ctx, func() { s.cancelled.Store(true) })
+	defer stopWatching()
+
+	start := s.newNode(*board, board.lowerBound())
+	s.nodes.set(board, start)
+	if board.isBoardEmpty() {
+		s.improveGoal(start, start.g)
+		return SolveResult{Moves: s.solution(), Complete: true}
+	}
+
+	first := SolveBeam(ctx, board, BeamOptions{
+		Width: anytimeBeamWidth,
+		Score: opts.Heuristic,
+	}).Moves
+	if first != nil {
+		s.improveGoal(pathNode(start, first), start.h)
+	}
+
+	weight := opts.InitialWeight
+	s.open.weight = weight
+	if s.goal == nil || start.h < s.goal.g {
+		heap.Push(s.open, start)
+	}
+
+	for {
+		s.open.weight = weight
+		s.reopen()
+
+		finished := s.improvePath()
+
+		if !finished {
+			return SolveResult{Moves: s.solution(), Complete: false}
+		}
+		// with a weight of 1 the search is plain A* on lowerBound, which has
+		// now either proven the solution or shown that there is none
+		lowest, ok := s.lowestBound()
+		if weight <= 1 || !ok || (s.goal != nil && s.goal.g <= lowest) {
+			return SolveResult{Moves: s.solution(), Complete: true}
+		}
+
+		weight -= opts.WeightStep
+		if weight < 1 {
+			weight = 1
+		}
+	}
+}
+
+// the beam search that finds the first solution for SolveAnytime takes well
+// under a second on a daily board
+const anytimeBeamWidth = 1000
+
+// pathNode returns the node reached by clicking moves from start. The nodes
+// on the way are not part of the search.
+func pathNode(start *araNode, moves []uint8) *araNode {
+	node := start
+	for _, pos := range moves {
+		node = &araNode{g: node.g + 1, parent: node, move: pos, index: -1}
+	}
+	return node
+}
+
+type araNode struct {
+	board  Board
+	g      int     // clicks used to reach this state
+	h      int     // lowerBound of the board
+	order  float32 // the Heuristic, orders the open list
+	parent *araNode
+	move   uint8
+	index  int // position in the open list, -1 if not in it
+	closed bool
+	incons bool
+}
+
+func (n *araNode) path() []uint8 {
+	moves := make([]uint8, n.g)
+	for node := n; node.parent != nil; node = node.parent {
+		moves[node.g-1] = node.move
+	}
+	return moves
+}
+
+// open list ordered on g + weight*order, deeper states first on ties
+type araOpen struct {
+	nodes  []*araNode
+	weight float32
+}
+
+func (o *araOpen) f(n *araNode) float32 {
+	return float32(n.g) + o.weight*n.order
+}
+
+func (o araOpen) Len() int { return len(o.nodes) }
+
+func (o araOpen) Less(i, j int) bool {
+	fi, fj := o.f(o.nodes[i]), o.f(o.nodes[j])
+	if fi != fj {
+		return fi < fj
+	}
+	return o.nodes[i].g > o.nodes[j].g
+}
+
+func (o araOpen) Swap(i, j int) {
+	o.nodes[i], o.nodes[j] = o.nodes[j], o.nodes[i]
+	o.nodes[i].index = i
+	o.nodes[j].index = j
+}
+
+func (o *araOpen) Push(x interface{}) {
+	n := x.(*araNode)
+	n.index = len(o.nodes)
+	o.nodes = append(o.nodes, n)
+}
+
+func (o *araOpen) Pop() interface{} {
+	old := o.nodes
+	n := old[len(old)-1]
+	n.index = -1
+	o.nodes = old[:len(old)-1]
+	return n
+}
+
+type araSearch struct {
+	heuristic  Heuristic
+	maxStates  int
+	cancelled  atomic.Bool
+	nodes      *stateMap[*araNode]
+	open       *araOpen
+	closed     []*araNode
+	incons     []*araNode // closed states that got a shorter path
+	goal       *araNode
+	onSolution func(AnytimeSolution)
+}
+
+// newNode creates the node of a state not seen before, h is its lowerBound
+func (s *araSearch) newNode(board Board, h int) *araNode {
+	n := &araNode{board: board, h: h, index: -1}
+	n.order = float32(h)
+	if s.heuristic != nil {
+		n.order = s.heuristic(&n.board)
+	}
+	return n
+}
+
+// reopen prepares the next search: states that got a shorter path after
+// they were expanded go back in the open list, and the open list is
+// reordered for the new weight.
+func (s *araSearch) reopen() {
+	for _, n := range s.closed {
+		n.closed = false
+	}
+	s.closed = s.closed[:0]
+
+	for _, n := range s.incons {
+		n.incons = false
+		if n.index < 0 {
+			n.index = len(s.open.nodes)
+			s.open.nodes = append(s.open.nodes, n)
+		}
+	}
+	s.incons = s.incons[:0]
+
+	heap.Init(s.open)
+}
+
+// improvePath expands states until no state in the open list can lead to a
+// shorter solution at the current weight. At weight 1 that is only when
+// lowerBound rules them all out, unless the Heuristic is lowerBound too. It
+// returns false if the context was cancelled or the search holds MaxStates
+// states first.
+func (s *araSearch) improvePath() bool {
+	stopEarly := s.open.weight > 1 || s.heuristic == nil
+	for s.open.Len() > 0 {
+		if s.cancelled.Load() || (s.maxStates > 0 && s.nodes.len() >= s.maxStates) {
+			return false
+		}
+
+		current := s.open.nodes[0]
+		if stopEarly && s.goal != nil && float32(s.goal.g) <= s.open.f(current) {
+			return true
+		}
+		heap.Pop(s.open)
+		current.closed = true
+		s.closed = append(s.closed, current)
+
+		// the estimate never overestimates, so this can't beat the solution
+		if s.goal != nil && current.g+current.h >= s.goal.g {
+			continue
+		}
+
+		for _, pos := range current.board.GetPossibleClicks() {
+			nextBoard := current.board
+			nextBoard.RemoveBricksIterative(pos)
+			nextBoard.Gravity()
+
+			g := current.g + 1
+			next, exists := s.nodes.get(&nextBoard)
+			if !exists {
+				// not kept if it can't beat the solution
+				h := nextBoard.lowerBound()
+				if s.goal != nil && g+h >= s.goal.g {
+					continue
+				}
+				next = s.newNode(nextBoard, h)
+				s.nodes.set(&nextBoard, next)
+			} else if next.g <= g {
+				continue
+			}
+			next.g = g
+			next.parent = current
+			next.move = pos
+
+			if nextBoard.isBoardEmpty() {
+				s.improveGoal(next, current.g+current.h)
+				continue
+			}
+			if s.goal != nil && next.g+next.h >= s.goal.g {
+				if next.index >= 0 {
+					heap.Remove(s.open, next.index)
+				}
+				continue
+			}
+
+			if next.closed {
+				if !next.incons {
+					next.incons = true
+					s.incons = append(s.incons, next)
+				}
+			} else if next.index >= 0 {
+				heap.Fix(s.open, next.index)
+			} else {
+				heap.Push(s.open, next)
+			}
+		}
+	}
+	return true
+}
+
+// improveGoal makes goal the solution, reports it, and drops the states that
+// can't lead to a shorter one. The state being expanded is not in the open
+// list, so its g + lowerBound is passed as expanding.
+func (s *araSearch) improveGoal(goal *araNode, expanding int) {
+	s.goal = goal
+
+	keep := func(n *araNode) bool {
+		return n.g+n.h < goal.g
+	}
+	s.nodes.deleteFunc(func(n *araNode) bool {
+		return !keep(n) && n != goal
+	})
+	open := s.open.nodes[:0]
+	for _, n := range s.open.nodes {
+		if keep(n) {
+			n.index = len(open)
+			open = append(open, n)
+		} else {
+			n.index = -1
+		}
+	}
+	clear(s.open.nodes[len(open):])
+	s.open.nodes = open
+	heap.Init(s.open)
+	s.closed = filterNodes(s.closed, keep)
+	s.incons = filterNodes(s.incons, keep)
+
+	if s.onSolution == nil {
+		return
+	}
+	// only the lowest estimate left bounds the solution
+	bound := float32(1)
+	lowest, ok := s.lowestBound()
+	if !ok || expanding < lowest {
+		lowest, ok = expanding, true
+	}
+	if lowest > 0 && goal.g > lowest {
+		bound = float32(goal.g) / float32(lowest)
+	}
+	s.onSolution(AnytimeSolution{
+		Moves:  goal.path(),
+		Weight: s.open.weight,
+		Bound:  bound,
+	})
+}
+
+func filterNodes(nodes []*araNode, keep func(*araNode) bool) []*araNode {
+	kept := nodes[:0]
+	for _, n := range nodes {
+		if keep(n) {
+			kept = append(kept, n)
+		}
+	}
+	clear(nodes[len(kept):])
+	return kept
+}
+
+// lowestBound returns the smallest g + lowerBound among the states that are
+// still waiting to be expanded. No solution can be shorter than this.
+func (s *araSearch) lowestBound() (int, bool) {
+	lowest, ok := 0, false
+	for _, list := range [][]*araNode{s.open.nodes, s.incons} {
+		for _, n := range list {
+			if !ok || n.g+n.h < lowest {
+				lowest, ok = n.g+n.h, true
+			}
+		}
+	}
+	return lowest, ok
+}
+
+func (s *araSearch) solution() []uint8 {
+	if s.goal == nil {
+		return nil
+	}
+	return s.goal.path()
+}
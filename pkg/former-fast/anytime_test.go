@@ -0,0 +1,59 @@
+package formerfast
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestSolveAnytimeAgainstBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for _, heuristic := range []Heuristic{nil, LogHeuristic(3.4)} {
+		for i := 0; i < 40; i++ {
+			board := randomBoard(t, r, 4, 5)
+			board.Gravity()
+			want := shortest(board, newStateMap[int]())
+
+			last := 0
+			result := SolveAnytime(context.Background(), board, AnytimeOptions{
+				InitialWeight: 3,
+				Heuristic:     heuristic,
+			}, func(s AnytimeSolution) {
+				if last > 0 && len(s.Moves) >= last {
+					t.Fatalf("solution of %d clicks reported after one of %d", len(s.Moves), last)
+				}
+				if float32(len(s.Moves)) > s.Bound*float32(want) {
+					t.Fatalf("solution of %d clicks is more than %.2f times the best, %d", len(s.Moves), s.Bound, want)
+				}
+				last = len(s.Moves)
+			})
+			if !result.Complete || len(result.Moves) != want {
+				t.Fatalf("%d clicks, complete %v, but the fewest are %d", len(result.Moves), result.Complete, want)
+			}
+			if verified, err := Verify(board, result.Moves); err != nil || !verified.Cleared() {
+				t.Fatalf("solution does not clear the board: %v", err)
+			}
+		}
+	}
+}
+
+func TestSolveAnytimeStops(t *testing.T) {
+	board := testBoards(t)["25-11-2024.json"]
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// the beam search is stopped as well, so there is no solution
+	result := SolveAnytime(cancelled, board, AnytimeOptions{}, nil)
+	if result.Complete || result.Moves != nil {
+		t.Errorf("cancelled context: complete %v with %d clicks", result.Complete, len(result.Moves))
+	}
+
+	// the beam search solution is there from the start
+	result = SolveAnytime(context.Background(), board, AnytimeOptions{MaxStates: 1000}, nil)
+	if result.Complete {
+		t.Error("max states: search is complete")
+	}
+	if verified, err := Verify(board, result.Moves); err != nil || !verified.Cleared() {
+		t.Errorf("max states: solution does not clear the board: %v", err)
+	}
+}
@@ -1,16 +1,46 @@
 package formerfast
 
 import (
-	"container/heap"
-	"math"
-	"sync"
+	"context"
 )
 
 type State struct {
 	Board    *Board
-	Moves    []uint8 // Sequence of moves to reach this state
-	Estimate float32 // h: Heuristic value
-	Priority float32 // f: Steps + Estimate
+	Path     *MoveNode // Last move made to reach this state, nil for the start
+	Estimate float32   // h: Heuristic value
+	Priority float32   // f: Steps + Estimate
+}
+
+// MoveNode is one click in the sequence of moves to reach a state. It points
+// back to the click before it, so all the states reached from the same state
+// share its moves instead of each having their own copy. The moves are only
+// turned into a slice when a solution is found.
+type MoveNode struct {
+	Parent *MoveNode
+	Pos    uint8
+	Depth  uint8 // number of clicks up to and including this one
+}
+
+// Then returns the path made by clicking pos after the clicks in n
+func (n *MoveNode) Then(pos uint8) *MoveNode {
+	return &MoveNode{Parent: n, Pos: pos, Depth: uint8(n.Len() + 1)}
+}
+
+// Len returns the number of clicks in the path, a nil path has none
+func (n *MoveNode) Len() int {
+	if n == nil {
+		return 0
+	}
+	return int(n.Depth)
+}
+
+// Moves returns the clicks in the path from first to last
+func (n *MoveNode) Moves() []uint8 {
+	moves := make([]uint8, n.Len())
+	for node := n; node != nil; node = node.Parent {
+		moves[node.Depth-1] = node.Pos
+	}
+	return moves
 }
 
 type PriorityQueue []*State
@@ -35,122 +65,38 @@ func (pq *PriorityQueue) Pop() interface{} {
 	return state
 }
 
-// Thread-safe priority queue
-type SafePriorityQueue struct {
-	pq    PriorityQueue
-	mutex sync.Mutex
-}
-
-func (spq *SafePriorityQueue) Len() int {
-	spq.mutex.Lock()
-	defer spq.mutex.Unlock()
-	return len(spq.pq)
+func SolveBoardUsingAStar(board *Board, maxThreads int, heuristicTuning float32) []uint8 {
+	result := Solve(context.Background(), board, SolveOptions{
+		MaxThreads:      maxThreads,
+		HeuristicTuning: heuristicTuning,
+	})
+	return result.Moves
 }
 
-func (spq *SafePriorityQueue) Push(state *State) {
-	spq.mutex.Lock()
-	heap.Push(&spq.pq, state)
-	spq.mutex.Unlock()
+// SolveBoardOptimal finds the shortest possible solution. Beam search first
+// finds a short solution, and then A* with lowerBound as the heuristic looks
+// for a shorter one. lowerBound never overestimates, so when A* is done no
+// shorter solution exists. This is a lot slower than SolveBoardUsingAStar
+// with a tuned heuristic, and on a full daily board it can run out of memory.
+func SolveBoardOptimal(board *Board, maxThreads int) []uint8 {
+	return SolveOptimal(context.Background(), board, maxThreads).Moves
 }
 
-func (spq *SafePriorityQueue) Pop() *State {
-	spq.mutex.Lock()
-	defer spq.mutex.Unlock()
-	if len(spq.pq) > 0 {
-		return heap.Pop(&spq.pq).(*State)
-	}
-	return nil
+// SolveOptimal is SolveBoardOptimal, but stops when ctx is cancelled or its
+// deadline passes. The result then has Complete set to false, and holds the
+// shortest solution found, which is not proven to be minimal.
+func SolveOptimal(ctx context.Context, board *Board, maxThreads int) SolveResult {
+	return Solve(ctx, board, SolveOptions{
+		MaxThreads: maxThreads,
+		Heuristic:  LowerBoundHeuristic(),
+		Incumbent: SolveBeam(ctx, board, BeamOptions{
+			Width:      optimalBeamWidth,
+			Score:      LogHeuristic(3.4),
+			MaxThreads: maxThreads,
+		}).Moves,
+	})
 }
 
-func SolveBoardUsingAStar(board *Board, maxThreads int, heuristicTuning float32) []uint8 {
-	spq := &SafePriorityQueue{pq: make(PriorityQueue, 0)}
-	heap.Init(&spq.pq)
-
-	initialState := &State{
-		Board:    board.Copy(),
-		Moves:    []uint8{},
-		Estimate: board.heuristic(heuristicTuning),
-		Priority: board.heuristic(heuristicTuning),
-	}
-
-	var wg sync.WaitGroup
-	var returnOnce sync.Once
-
-	numThreadsSemephore := make(chan struct{}, maxThreads)
-
-	result := make(chan []uint8, 1)
-	done := make(chan struct{})
-
-	spq.Push(initialState)
-
-	for {
-		select {
-		case <-done:
-			return <-result
-		default:
-			current := spq.Pop()
-
-			if current == nil {
-				isQueueEmpty := spq.Len() == 0
-				if !isQueueEmpty {
-					continue
-				}
-				wg.Wait() // wait for unfinnished threads, and check queue again
-				isQueueStillEmpty := spq.Len() == 0
-				// if queue is empty we did not find a solution
-				if isQueueStillEmpty {
-					return nil
-				}
-				// We are consuming items to fast from the queue,
-				// and need to continue
-				continue
-			}
-
-			numThreadsSemephore <- struct{}{}
-			wg.Add(1)
-			go func(state *State) {
-				defer wg.Done()
-				defer func() { <-numThreadsSemephore }()
-
-				if state.Board.isBoardEmpty() {
-					result <- state.Moves
-					returnOnce.Do(func() { close(done) })
-					return
-				}
-
-				possibleClicks := state.Board.GetPossibleClicks()
-
-				for _, pos := range possibleClicks {
-					nextBoard := state.Board.Copy()
-					nextBoard.RemoveBricksIterative(pos)
-					nextBoard.Gravity()
-
-					estimatedDistance := nextBoard.heuristic(heuristicTuning)
-
-					nextMoves := append([]uint8{}, state.Moves...)
-					nextMoves = append(nextMoves, pos)
-
-					nextState := &State{
-						Board:    nextBoard,
-						Moves:    nextMoves,
-						Estimate: estimatedDistance,
-						Priority: float32(len(nextMoves)) + estimatedDistance,
-					}
-
-					spq.Push(nextState)
-				}
-			}(current)
-		}
-
-	}
-}
-
-// Estimates amount of clicks needed to win the game from the current game state
-// The assumptions is that when there a lot of options to choose from many of them
-// are not real clicks you need to click since blocks merge over time.
-// The fewer click options we have, the higher chache is it that the click is real.
-// TODO: improve this to get better estimate to goal
-func (board *Board) heuristic(heuristicTuning float32) float32 {
-	possibleClicks := board.GetPossibleClicks()
-	return float32(math.Log(float64(len(possibleClicks)))) * heuristicTuning
-}
+// the beam search that finds the first solution for SolveOptimal takes well
+// under a second on a daily board
+const optimalBeamWidth = 1000
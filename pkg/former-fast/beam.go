@@ -0,0 +1,120 @@
+package formerfast
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+type BeamOptions struct {
+	Width      int       // number of states kept in each layer, at least 1
+	Score      Heuristic // ranks the states in a layer, lower is better. LogHeuristic(1) if nil
+	MaxThreads int       // number of goroutines expanding the beam, at least 1
+}
+
+// SolveBoardUsingBeamSearch finds a good solution fast, but not always the
+// best one. It searches one click at a time, and after each click only keeps
+// the Width states with the lowest score. Boards that are reached in more
+// than one way are only kept once. The search stops at the first layer where
+// a board is cleared, or returns nil if the beam runs empty before that.
+func SolveBoardUsingBeamSearch(board *Board, opts BeamOptions) []uint8 {
+	return SolveBeam(context.Background(), board, opts).Moves
+}
+
+// SolveBeam is SolveBoardUsingBeamSearch, but stops when ctx is cancelled or
+// its deadline passes. The context is checked between layers. A search that
+// was stopped has no solution yet, so the result then has Complete set to
+// false and no moves.
+func SolveBeam(ctx context.Context, board *Board, opts BeamOptions) SolveResult {
+	if opts.Width < 1 {
+		opts.Width = 1
+	}
+	if opts.Score == nil {
+		opts.Score = LogHeuristic(1)
+	}
+	if opts.MaxThreads < 1 {
+		opts.MaxThreads = 1
+	}
+
+	if board.isBoardEmpty() {
+		return SolveResult{Moves: []uint8{}, Complete: true}
+	}
+
+	beam := []beamState{{board: *board}}
+	for len(beam) > 0 {
+		if ctx.Err() != nil {
+			return SolveResult{Complete: false}
+		}
+		children := expandBeam(beam, opts)
+
+		layer := make([]beamState, 0, len(children))
+		seen := newStateMap[bool]()
+		for _, child := range children {
+			if child.board.isBoardEmpty() {
+				return SolveResult{Moves: child.path.Moves(), Complete: true}
+			}
+			if _, exists := seen.get(&child.board); exists {
+				continue
+			}
+			seen.set(&child.board, true)
+			layer = append(layer, child)
+		}
+
+		sort.SliceStable(layer, func(i, j int) bool {
+			return layer[i].score < layer[j].score
+		})
+		if len(layer) > opts.Width {
+			layer = layer[:opts.Width]
+		}
+		beam = layer
+	}
+	return SolveResult{Complete: true}
+}
+
+type beamState struct {
+	board Board
+	path  *MoveNode
+	score float32
+}
+
+// expandBeam returns the children of every state in the beam, scored. The
+// beam is split into one chunk per goroutine, and the children are returned
+// in the same order as a single goroutine would make them.
+func expandBeam(beam []beamState, opts BeamOptions) []beamState {
+	numChunks := opts.MaxThreads
+	if numChunks > len(beam) {
+		numChunks = len(beam)
+	}
+	chunkSize := (len(beam) + numChunks - 1) / numChunks
+	numChunks = (len(beam) + chunkSize - 1) / chunkSize
+	results := make([][]beamState, numChunks)
+
+	var wg sync.WaitGroup
+	for i := 0; i < numChunks; i++ {
+		start := i * chunkSize
+		end := min(start+chunkSize, len(beam))
+
+		wg.Add(1)
+		go func(i int, chunk []beamState) {
+			defer wg.Done()
+			for _, state := range chunk {
+				for _, pos := range state.board.GetPossibleClicks() {
+					child := beamState{board: state.board, path: state.path.Then(pos)}
+					child.board.RemoveBricksIterative(pos)
+					child.board.Gravity()
+					if !child.board.isBoardEmpty() {
+						child.score = opts.Score(&child.board)
+					}
+					results[i] = append(results[i], child)
+				}
+			}
+		}(i, beam[start:end])
+	}
+	wg.Wait()
+
+	children := []beamState{}
+	for _, result := range results {
+		children = append(children, result...)
+	}
+	return children
+}
@@ -0,0 +1,89 @@
+package formerfast
+
+import (
+	"fmt"
+
+	"github.com/martcl/nrk-former/pkg/former"
+)
+
+// the two packages number the colors differently, green is 0 in former and
+// orange is 0 here
+var (
+	fromFormerColor = map[former.BrickType]BrickType{
+		former.Green:  green,
+		former.Blue:   blue,
+		former.Orange: orange,
+		former.Pink:   pink,
+	}
+	toFormerColor = [4]former.BrickType{
+		orange: former.Orange,
+		green:  former.Green,
+		pink:   former.Pink,
+		blue:   former.Blue,
+	}
+)
+
+// FromFormerBoard converts a former.Board to a Board with the same size and
+// bricks, so boards made with the readable package can be solved here
+func FromFormerBoard(board *former.Board) (*Board, error) {
+	if len(board.Bricks) != board.Width*board.Height {
+		return nil, fmt.Errorf("board has %d bricks, expected %d", len(board.Bricks), board.Width*board.Height)
+	}
+
+	b, err := NewBoard(board.Width, board.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	for index, brick := range board.Bricks {
+		if brick == nil {
+			continue
+		}
+		color, exists := fromFormerColor[brick.Type]
+		if !exists {
+			return nil, fmt.Errorf("unknown brick type %d at index %d", brick.Type, index)
+		}
+		b.SetBrick(uint8(index), color)
+	}
+	return b, nil
+}
+
+// ToFormerBoard converts the board to a former.Board with the same size and
+// bricks. Empty cells become nil bricks.
+func (b *Board) ToFormerBoard() *former.Board {
+	board := &former.Board{
+		Width:  b.Width(),
+		Height: b.Height(),
+		Bricks: make([]*former.Brick, b.Width()*b.Height()),
+	}
+
+	for index := range board.Bricks {
+		if brick, err := b.GetBrick(uint8(index)); err == nil {
+			board.Bricks[index] = &former.Brick{Type: toFormerColor[brick]}
+		}
+	}
+	return board
+}
+
+// ToClick returns the former.Click for the cell at pos
+func (b *Board) ToClick(pos uint8) former.Click {
+	x, y := b.XY(pos)
+	return former.Click{X: x, Y: y}
+}
+
+// ToClicks converts a solution from this package to former.Click values
+func (b *Board) ToClicks(moves []uint8) []former.Click {
+	clicks := make([]former.Click, len(moves))
+	for i, pos := range moves {
+		clicks[i] = b.ToClick(pos)
+	}
+	return clicks
+}
+
+// FromClick returns the position of the cell a former.Click points at
+func (b *Board) FromClick(click former.Click) (uint8, error) {
+	if click.X < 0 || click.X >= b.Width() || click.Y < 0 || click.Y >= b.Height() {
+		return 0, fmt.Errorf("click (%d, %d) is outside the board", click.X, click.Y)
+	}
+	return b.Pos(click.X, click.Y), nil
+}
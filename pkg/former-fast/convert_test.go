@@ -0,0 +1,77 @@
+package formerfast
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/martcl/nrk-former/pkg/former"
+)
+
+func TestFormerBoardRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	for _, size := range testSizes {
+		for i := 0; i < 100; i++ {
+			board := randomBoard(t, r, size[0], size[1])
+
+			got, err := FromFormerBoard(board.ToFormerBoard())
+			if err != nil {
+				t.Fatalf("%dx%d: %v", size[0], size[1], err)
+			}
+			if !got.Equal(board) || got.Hash() != board.Hash() {
+				t.Fatalf("%dx%d: board changed by the round trip", size[0], size[1])
+			}
+		}
+	}
+}
+
+func TestFromFormerBoardMatchesLoadBoard(t *testing.T) {
+	for name, want := range testBoards(t) {
+		data, err := os.ReadFile("../../tests/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		board, err := former.LoadBoard(string(data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		got, err := FromFormerBoard(board)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !got.Equal(want) || got.Hash() != want.Hash() {
+			t.Errorf("%s: converted board differs from the one loaded by LoadBoard", name)
+		}
+	}
+}
+
+func TestFromFormerBoardErrors(t *testing.T) {
+	board := &former.Board{Width: 2, Height: 2, Bricks: make([]*former.Brick, 3)}
+	if _, err := FromFormerBoard(board); err == nil {
+		t.Error("no error for a board with too few bricks")
+	}
+
+	board.Bricks = append(board.Bricks, &former.Brick{Type: 7})
+	if _, err := FromFormerBoard(board); err == nil {
+		t.Error("no error for an unknown brick type")
+	}
+}
+
+func TestToClickFromClick(t *testing.T) {
+	board, err := NewBoard(7, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pos := uint8(0); pos < 63; pos++ {
+		got, err := board.FromClick(board.ToClick(pos))
+		if err != nil || got != pos {
+			t.Fatalf("position %d came back as %d, %v", pos, got, err)
+		}
+	}
+	for _, click := range []former.Click{{X: -1, Y: 0}, {X: 7, Y: 0}, {X: 0, Y: 9}, {X: 0, Y: -1}} {
+		if _, err := board.FromClick(click); err == nil {
+			t.Errorf("no error for click (%d, %d) outside the board", click.X, click.Y)
+		}
+	}
+}
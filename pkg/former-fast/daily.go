@@ -0,0 +1,72 @@
+package formerfast
+
+import (
+	"time"
+	_ "time/tzdata"
+)
+
+// the game changes board at midnight in Norway
+var oslo = mustLoadLocation("Europe/Oslo")
+
+func mustLoadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return location
+}
+
+// Clock returns the current time. It is time.Now, unless a tool needs to
+// pretend it is another day.
+type Clock func() time.Time
+
+// Daily is the board of one day, and how it was made
+type Daily struct {
+	Day        time.Time // midnight at the start of the day, in Europe/Oslo
+	DateString string    // the date the seed is made from
+	Seed       string    // md5 of DateString
+	Board      *Board
+}
+
+// DailyBoard returns the board for the day date is in, in Europe/Oslo. The
+// zone of date does not matter, so 23:30 UTC on a summer evening is the next
+// day's board, since it is 01:30 in Norway. The tzdata is embedded, so this
+// works on servers without it.
+func DailyBoard(date time.Time) *Daily {
+	local := date.In(oslo)
+	year, month, day := local.Date()
+
+	// for some reason they have the dates mixed up
+	// and have some sort of offset. The API said it
+	// was -60, but that is not true. `go run ./cmd calibrate`
+	// finds that -31 days reproduces every board in tests/,
+	// but so does counting months from 0 like getMonth in
+	// JavaScript. The two only differ when the month before
+	// has less than 31 days.
+	// TODO: add boards from such months to tests/ to tell them apart
+	const offset = -31
+
+	// count days on the calendar, in a zone without daylight saving time,
+	// so the hour lost or gained in spring and autumn can't move the date
+	seedDate := time.Date(year, month, day+offset, 12, 0, 0, 0, time.UTC)
+	dateString := seedDate.Format("02012006")
+	seed := MD5Seed(dateString)
+
+	board, _ := CreateBoardWithPseudoRandom(9, 7, InitializeRandomState(seed))
+
+	return &Daily{
+		Day:        time.Date(year, month, day, 0, 0, 0, 0, oslo),
+		DateString: dateString,
+		Seed:       seed,
+		Board:      board,
+	}
+}
+
+// TodaysBoard returns the board that is played right now according to clock,
+// or time.Now if clock is nil
+func TodaysBoard(clock Clock) *Daily {
+	if clock == nil {
+		clock = time.Now
+	}
+	return DailyBoard(clock())
+}
@@ -0,0 +1,84 @@
+package formerfast
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDailyBoardDay(t *testing.T) {
+	for _, test := range []struct {
+		utc        string // the time DailyBoard is called with, in UTC
+		day        string // the day in Norway
+		dateString string
+	}{
+		// Norway is UTC+1 in winter and UTC+2 in summer
+		{"2024-11-25T22:59:59Z", "2024-11-25", "25102024"},
+		{"2024-11-25T23:00:00Z", "2024-11-26", "26102024"},
+		{"2024-07-01T21:59:59Z", "2024-07-01", "31052024"},
+		{"2024-07-01T22:00:00Z", "2024-07-02", "01062024"},
+
+		// summer time starts at 01:00 UTC on 31 March 2024
+		{"2024-03-30T22:59:59Z", "2024-03-30", "28022024"},
+		{"2024-03-30T23:00:00Z", "2024-03-31", "29022024"},
+		{"2024-03-31T00:59:59Z", "2024-03-31", "29022024"},
+		{"2024-03-31T01:00:00Z", "2024-03-31", "29022024"},
+		{"2024-03-31T21:59:59Z", "2024-03-31", "29022024"},
+		{"2024-03-31T22:00:00Z", "2024-04-01", "01032024"},
+
+		// and ends at 01:00 UTC on 27 October 2024
+		{"2024-10-26T21:59:59Z", "2024-10-26", "25092024"},
+		{"2024-10-26T22:00:00Z", "2024-10-27", "26092024"},
+		{"2024-10-27T00:59:59Z", "2024-10-27", "26092024"},
+		{"2024-10-27T01:00:00Z", "2024-10-27", "26092024"},
+		{"2024-10-27T22:59:59Z", "2024-10-27", "26092024"},
+		{"2024-10-27T23:00:00Z", "2024-10-28", "27092024"},
+
+		// the offset counts days, so it goes back past short months and
+		// into the year before instead of subtracting one from the month
+		{"2024-03-30T12:00:00Z", "2024-03-30", "28022024"},
+		{"2024-03-29T12:00:00Z", "2024-03-29", "27022024"},
+		{"2023-03-31T12:00:00Z", "2023-03-31", "28022023"},
+		{"2023-03-29T12:00:00Z", "2023-03-29", "26022023"},
+		{"2024-03-01T12:00:00Z", "2024-03-01", "30012024"},
+		{"2024-05-31T12:00:00Z", "2024-05-31", "30042024"},
+		{"2025-01-15T12:00:00Z", "2025-01-15", "15122024"},
+		{"2025-01-01T00:30:00Z", "2025-01-01", "01122024"},
+		{"2024-12-31T23:30:00Z", "2025-01-01", "01122024"},
+	} {
+		date, err := time.Parse(time.RFC3339, test.utc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		daily := DailyBoard(date)
+
+		if day := daily.Day.Format("2006-01-02"); day != test.day {
+			t.Errorf("%s: day %s, expected %s", test.utc, day, test.day)
+		}
+		if daily.Day.Hour() != 0 || daily.Day.Minute() != 0 || daily.Day.Location() != oslo {
+			t.Errorf("%s: day starts at %s, expected midnight in Europe/Oslo", test.utc, daily.Day)
+		}
+		if daily.DateString != test.dateString {
+			t.Errorf("%s: date string %s, expected %s", test.utc, daily.DateString, test.dateString)
+		}
+		if daily.Seed != MD5Seed(test.dateString) {
+			t.Errorf("%s: seed %s is not the md5 of %s", test.utc, daily.Seed, test.dateString)
+		}
+	}
+}
+
+func TestDailyBoardMatchesTests(t *testing.T) {
+	for name, want := range testBoards(t) {
+		day, err := time.ParseInLocation("02-01-2006", strings.TrimSuffix(name, ".json"), oslo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// any time of the day gives the same board
+		for _, hour := range []int{0, 12, 23} {
+			daily := DailyBoard(day.Add(time.Duration(hour) * time.Hour))
+			if !daily.Board.Equal(want) {
+				t.Errorf("%s: board at %02d:00 differs from the test board", name, hour)
+			}
+		}
+	}
+}
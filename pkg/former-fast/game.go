@@ -0,0 +1,133 @@
+package formerfast
+
+import (
+	"fmt"
+)
+
+// Move is one click that was played in a Game
+type Move struct {
+	Click   uint8 // the position that was clicked
+	Removed Group // the group the click removed
+}
+
+// Game plays clicks on a board one at a time, the way a player would, and
+// remembers every move so they can be undone and redone.
+type Game struct {
+	board   Board
+	history []gameStep // moves played, the last one is undone first
+	undone  []gameStep // moves undone, the last one is redone first
+}
+
+type gameStep struct {
+	before Board // the board before the move
+	move   Move
+}
+
+// NewGame starts a game on a copy of board
+func NewGame(board *Board) *Game {
+	return &Game{board: *board}
+}
+
+// Board returns a copy of the board as it is now
+func (g *Game) Board() *Board {
+	return g.board.Copy()
+}
+
+// Click removes the group at pos and lets the bricks above it fall. It
+// returns an error and leaves the board as it was if pos is outside the board
+// or empty. Clicking clears the moves that could be redone.
+func (g *Game) Click(pos uint8) (Move, error) {
+	color, err := g.board.GetBrick(pos)
+	if err != nil {
+		return Move{}, fmt.Errorf("can't click position %d: %w", pos, err)
+	}
+
+	mask := g.board.ConnectedBricks(pos)
+	step := gameStep{
+		before: g.board,
+		move: Move{
+			Click: pos,
+			Removed: Group{
+				Click: mask.highest(),
+				Color: color,
+				Size:  mask.onesCount(),
+				Mask:  mask,
+			},
+		},
+	}
+
+	g.board.RemoveGroup(mask)
+	g.board.Gravity()
+	g.history = append(g.history, step)
+	g.undone = g.undone[:0]
+	return step.move, nil
+}
+
+// Play clicks every position in moves in order, and stops at the first click
+// that fails
+func (g *Game) Play(moves []uint8) error {
+	for i, pos := range moves {
+		if _, err := g.Click(pos); err != nil {
+			return fmt.Errorf("click %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Undo takes back the last move, and returns it
+func (g *Game) Undo() (Move, error) {
+	if len(g.history) == 0 {
+		return Move{}, fmt.Errorf("no moves to undo")
+	}
+
+	step := g.history[len(g.history)-1]
+	g.history = g.history[:len(g.history)-1]
+	g.undone = append(g.undone, step)
+	g.board = step.before
+	return step.move, nil
+}
+
+// Redo plays the last undone move again, and returns it
+func (g *Game) Redo() (Move, error) {
+	if len(g.undone) == 0 {
+		return Move{}, fmt.Errorf("no moves to redo")
+	}
+
+	step := g.undone[len(g.undone)-1]
+	g.undone = g.undone[:len(g.undone)-1]
+	g.history = append(g.history, step)
+
+	g.board = step.before
+	g.board.RemoveGroup(step.move.Removed.Mask)
+	g.board.Gravity()
+	return step.move, nil
+}
+
+// CanUndo reports whether there is a move to undo
+func (g *Game) CanUndo() bool {
+	return len(g.history) > 0
+}
+
+// CanRedo reports whether there is an undone move to redo
+func (g *Game) CanRedo() bool {
+	return len(g.undone) > 0
+}
+
+// Clicks returns the number of clicks played so far, not counting undone ones
+func (g *Game) Clicks() int {
+	return len(g.history)
+}
+
+// History returns the moves played so far, from first to last
+func (g *Game) History() []Move {
+	moves := make([]Move, len(g.history))
+	for i, step := range g.history {
+		moves[i] = step.move
+	}
+	return moves
+}
+
+// IsCleared reports whether every brick has been removed
+func (g *Game) IsCleared() bool {
+	return g.board.isBoardEmpty()
+}
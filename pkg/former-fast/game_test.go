@@ -0,0 +1,117 @@
+package formerfast
+
+import (
+	"testing"
+)
+
+func TestGameUndoRedo(t *testing.T) {
+	start := testBoards(t)["25-11-2024.json"]
+	game := NewGame(start)
+	if game.CanUndo() || game.CanRedo() {
+		t.Fatal("new game has moves to undo or redo")
+	}
+	if _, err := game.Undo(); err == nil {
+		t.Error("no error for undo in a new game")
+	}
+	if _, err := game.Redo(); err == nil {
+		t.Error("no error for redo in a new game")
+	}
+
+	// play the first possible click until the board is clear, and keep the
+	// board after every move
+	boards := []Board{*game.Board()}
+	moves := []Move{}
+	for !game.IsCleared() {
+		pos := game.Board().GetPossibleClicks()[0]
+		want := game.Board()
+		removed := want.ConnectedBricks(pos)
+		want.RemoveGroup(removed)
+		want.Gravity()
+
+		move, err := game.Click(pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if move.Click != pos || move.Removed.Mask != removed || move.Removed.Size != removed.onesCount() {
+			t.Fatalf("click %d: got move %+v", len(moves), move)
+		}
+		if *game.Board() != *want {
+			t.Fatalf("click %d: board differs from removing the group by hand", len(moves))
+		}
+		boards = append(boards, *game.Board())
+		moves = append(moves, move)
+	}
+	if game.Clicks() != len(moves) || len(game.History()) != len(moves) {
+		t.Fatalf("%d clicks and %d moves in the history, played %d", game.Clicks(), len(game.History()), len(moves))
+	}
+
+	// undo every move, then redo them all
+	for i := len(moves) - 1; i >= 0; i-- {
+		move, err := game.Undo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if move != moves[i] || *game.Board() != boards[i] {
+			t.Fatalf("undo of click %d gave the wrong move or board", i)
+		}
+	}
+	if game.CanUndo() || game.Clicks() != 0 {
+		t.Fatal("moves left to undo after undoing every move")
+	}
+	for i := range moves {
+		move, err := game.Redo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if move != moves[i] || *game.Board() != boards[i+1] {
+			t.Fatalf("redo of click %d gave the wrong move or board", i)
+		}
+	}
+	if game.CanRedo() || !game.IsCleared() {
+		t.Fatal("redoing every move did not clear the board")
+	}
+
+	// a new click after undoing clears the moves that could be redone
+	game.Undo()
+	game.Undo()
+	if !game.CanRedo() {
+		t.Fatal("nothing to redo after two undos")
+	}
+	if _, err := game.Click(moves[len(moves)-2].Click); err != nil {
+		t.Fatal(err)
+	}
+	if game.CanRedo() {
+		t.Error("moves left to redo after a new click")
+	}
+	history := game.History()
+	if len(history) != len(moves)-1 || history[len(history)-1] != moves[len(moves)-2] {
+		t.Errorf("history has %d moves after the new click, expected %d", len(history), len(moves)-1)
+	}
+}
+
+func TestGameClickErrors(t *testing.T) {
+	game := NewGame(testBoards(t)["25-11-2024.json"])
+	if _, err := game.Click(game.Board().GetPossibleClicks()[0]); err != nil {
+		t.Fatal(err)
+	}
+	before := *game.Board()
+
+	empty := uint8(0)
+	for ; empty < 63; empty++ {
+		if _, err := before.GetBrick(empty); err != nil {
+			break
+		}
+	}
+	if empty == 63 {
+		t.Fatal("no empty cell after a click")
+	}
+
+	for _, pos := range []uint8{empty, 63, 200} {
+		if _, err := game.Click(pos); err == nil {
+			t.Errorf("no error for clicking %d", pos)
+		}
+		if *game.Board() != before || game.Clicks() != 1 {
+			t.Errorf("clicking %d changed the game", pos)
+		}
+	}
+}
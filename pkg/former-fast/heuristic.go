@@ -0,0 +1,182 @@
+package formerfast
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// Heuristic estimates how many clicks are needed to clear the board.
+// The solvers call it from many goroutines at once.
+type Heuristic func(board *Board) float32
+
+// LogHeuristic is the natural logarithm of the number of possible clicks,
+// multiplied by heuristicTuning. See Board.heuristic.
+func LogHeuristic(heuristicTuning float32) Heuristic {
+	return func(board *Board) float32 {
+		return board.heuristic(heuristicTuning)
+	}
+}
+
+// LowerBoundHeuristic never overestimates the number of clicks left. See
+// Board.lowerBound.
+func LowerBoundHeuristic() Heuristic {
+	return func(board *Board) float32 {
+		return float32(board.lowerBound())
+	}
+}
+
+type WeightedHeuristic struct {
+	Weight    float32
+	Heuristic Heuristic
+}
+
+// CombinedHeuristic adds up the estimates of several heuristics, each
+// multiplied by its weight
+func CombinedHeuristic(terms ...WeightedHeuristic) Heuristic {
+	return func(board *Board) float32 {
+		estimate := float32(0)
+		for _, term := range terms {
+			estimate += term.Weight * term.Heuristic(board)
+		}
+		return estimate
+	}
+}
+
+var heuristicsByName = map[string]func(heuristicTuning float32) Heuristic{
+	"log": LogHeuristic,
+	"lowerbound": func(float32) Heuristic {
+		return LowerBoundHeuristic()
+	},
+	// the lower bound counts clicks that are needed for sure,
+	// and the log part guesses how many more there are
+	"combined": func(heuristicTuning float32) Heuristic {
+		return CombinedHeuristic(
+			WeightedHeuristic{Weight: 1, Heuristic: LowerBoundHeuristic()},
+			WeightedHeuristic{Weight: 1, Heuristic: LogHeuristic(heuristicTuning)},
+		)
+	},
+}
+
+// HeuristicByName returns one of the built in heuristics by the name listed
+// in HeuristicNames. The ones that can be tuned use heuristicTuning.
+func HeuristicByName(name string, heuristicTuning float32) (Heuristic, error) {
+	create, exists := heuristicsByName[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown heuristic %q", name)
+	}
+	return create(heuristicTuning), nil
+}
+
+// HeuristicNames returns the names accepted by HeuristicByName
+func HeuristicNames() []string {
+	names := make([]string, 0, len(heuristicsByName))
+	for name := range heuristicsByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Estimates amount of clicks needed to win the game from the current game state
+// The assumptions is that when there a lot of options to choose from many of them
+// are not real clicks you need to click since blocks merge over time.
+// The fewer click options we have, the higher chache is it that the click is real.
+// TODO: improve this to get better estimate to goal
+func (board *Board) heuristic(heuristicTuning float32) float32 {
+	return float32(math.Log(float64(board.CountGroups()))) * heuristicTuning
+}
+
+// Lower bound on the number of clicks needed to clear the board: one per run
+// of neighbouring columns that has a color, and for colors that are only in
+// one column, the clicks that column needs for them. Each click lowers it by
+// at most one. Why this holds is explained in the readme.
+func (board *Board) lowerBound() int {
+	l := board.geometry()
+	columns := [4]uint64{}
+	for c := orange; c <= blue; c++ {
+		for x, column := range l.columns {
+			if !board.plane(c).and(column).isZero() {
+				columns[c] |= 1 << x
+			}
+		}
+	}
+
+	bound := 0
+	single := [4]uint64{}
+	for c := orange; c <= blue; c++ {
+		single[c] = columns[c] &^ (columns[c] << 1) &^ (columns[c] >> 1)
+		// count the first column of every run
+		bound += bits.OnesCount64(columns[c] &^ (columns[c] << 1))
+	}
+
+	// a single column colors need more than one click each only if there
+	// are at least two of them in the column
+	shared := single[orange]&(single[green]|single[pink]|single[blue]) |
+		single[green]&(single[pink]|single[blue]) |
+		single[pink]&single[blue]
+	for ; shared != 0; shared &= shared - 1 {
+		x := bits.TrailingZeros64(shared)
+		colors := [4]bool{}
+		for c := orange; c <= blue; c++ {
+			colors[c] = single[c]&(1<<x) != 0
+		}
+		blocks, count := board.columnBlocks(x, colors)
+		bound += clearClicks(blocks) - count
+	}
+	return bound
+}
+
+// columnBlocks returns the colors of column x from the top, for the given
+// colors only and with neighbours of the same color joined into one block.
+// It also returns how many different colors there are.
+func (board *Board) columnBlocks(x int, colors [4]bool) ([]int, int) {
+	l := board.geometry()
+	planes := board.planes()
+	blocks := make([]int, 0, l.height)
+	seen := [4]bool{}
+	count := 0
+	for y := 0; y < l.height; y++ {
+		pos := uint8(y*l.width + x)
+		for c := orange; c <= blue; c++ {
+			if !colors[c] || !planes[c].has(pos) {
+				continue
+			}
+			if len(blocks) == 0 || blocks[len(blocks)-1] != c {
+				blocks = append(blocks, c)
+			}
+			if !seen[c] {
+				seen[c] = true
+				count++
+			}
+		}
+	}
+	return blocks, count
+}
+
+// clearClicks returns the fewest clicks that remove every block, when a click
+// removes one block and the blocks on each side of it join if they have the
+// same color. For blocks i to j, block i is either
+// removed on its own, or joined with the next block k of its color once the
+// blocks between them are gone.
+func clearClicks(blocks []int) int {
+	n := len(blocks)
+	if n == 0 {
+		return 0
+	}
+	// clicks[i*(n+1)+j], with the empty ranges left at zero
+	clicks := make([]int, (n+1)*(n+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := i; j < n; j++ {
+			best := 1 + clicks[(i+1)*(n+1)+j]
+			for k := i + 1; k <= j; k++ {
+				if blocks[k] == blocks[i] {
+					best = min(best, clicks[(i+1)*(n+1)+k-1]+clicks[k*(n+1)+j])
+				}
+			}
+			clicks[i*(n+1)+j] = best
+		}
+	}
+	return clicks[n-1]
+}
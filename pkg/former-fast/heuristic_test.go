@@ -0,0 +1,88 @@
+package formerfast
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+// shortest returns the fewest clicks needed to clear every board reachable
+// from board, found by trying every click
+func shortest(board *Board, known *stateMap[int]) int {
+	if clicks, exists := known.get(board); exists {
+		return clicks
+	}
+	best := 0
+	if !board.isBoardEmpty() {
+		best = board.Width() * board.Height()
+		for _, pos := range board.GetPossibleClicks() {
+			next := board.Copy()
+			next.RemoveBricksIterative(pos)
+			next.Gravity()
+			best = min(best, 1+shortest(next, known))
+		}
+	}
+	known.set(board, best)
+	return best
+}
+
+func TestLowerBoundAgainstBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	for _, size := range [][2]int{{1, 8}, {2, 5}, {3, 4}, {4, 4}, {5, 3}} {
+		for i := 0; i < 50; i++ {
+			board := randomBoard(t, r, size[0], size[1])
+			board.Gravity()
+			known := newStateMap[int]()
+			shortest(board, known)
+
+			// check every state the brute force reached, and every click from it
+			states := []*Board{board}
+			checked := newStateMap[bool]()
+			checked.set(board, true)
+			for len(states) > 0 {
+				state := states[len(states)-1]
+				states = states[:len(states)-1]
+				clicks, _ := known.get(state)
+				bound := state.lowerBound()
+				if bound > clicks {
+					t.Fatalf("%dx%d: lower bound %d, but %d clicks clear the board", size[0], size[1], bound, clicks)
+				}
+				// every color is in a single column, so the bound is exact
+				if size[0] == 1 && bound != clicks {
+					t.Fatalf("1x%d: lower bound %d, but the fewest clicks are %d", size[1], bound, clicks)
+				}
+				for _, pos := range state.GetPossibleClicks() {
+					next := state.Copy()
+					next.RemoveBricksIterative(pos)
+					next.Gravity()
+					if next.lowerBound() < bound-1 {
+						t.Fatalf("%dx%d: one click lowered the bound from %d to %d", size[0], size[1], bound, next.lowerBound())
+					}
+					if _, exists := checked.get(next); !exists {
+						checked.set(next, true)
+						states = append(states, next)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSolveOptimalAgainstBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(6))
+	for _, size := range [][2]int{{3, 4}, {4, 4}, {5, 4}, {4, 6}} {
+		for i := 0; i < 20; i++ {
+			board := randomBoard(t, r, size[0], size[1])
+			board.Gravity()
+			want := shortest(board, newStateMap[int]())
+
+			result := SolveOptimal(context.Background(), board, 2)
+			if !result.Complete || len(result.Moves) != want {
+				t.Fatalf("%dx%d: %d clicks, complete %v, but the fewest are %d", size[0], size[1], len(result.Moves), result.Complete, want)
+			}
+			if verified, err := Verify(board, result.Moves); err != nil || !verified.Cleared() {
+				t.Fatalf("%dx%d: solution does not clear the board: %v", size[0], size[1], err)
+			}
+		}
+	}
+}
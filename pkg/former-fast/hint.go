@@ -0,0 +1,122 @@
+package formerfast
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+type HintOptions struct {
+	Count      int // number of hints to return, 3 if 0
+	BeamWidth  int // width of the beam search run after each click, 100 if 0
+	MaxThreads int // clicks evaluated at once, at least 1
+}
+
+// Hint is a suggested next click
+type Hint struct {
+	Click   uint8
+	Removed Group // the group the click removes
+	// Clicks is the number of clicks left to clear the board when starting
+	// with this click, including it, in the best solution found. It is an
+	// estimate: a shorter solution may exist.
+	Clicks int
+	// No solution starting with this click can use fewer clicks than this
+	LowerBound int
+	Solution   []uint8 // the solution Clicks is based on, starting with Click
+}
+
+// GetHints suggests the next click on a board that may already be partly
+// played, like one loaded from the game in the middle of the day. Every
+// possible click is tried, and the rest of the board is solved with a beam
+// search. The hints are ordered by the length of the solution found, best
+// first.
+//
+// The clicks that look best are evaluated first. If ctx is done before every
+// click has been evaluated, the beam searches still running are stopped and
+// only the clicks evaluated by then are ranked.
+func GetHints(ctx context.Context, board *Board, opts HintOptions) []Hint {
+	if opts.Count < 1 {
+		opts.Count = 3
+	}
+	if opts.BeamWidth < 1 {
+		opts.BeamWidth = 100
+	}
+	if opts.MaxThreads < 1 {
+		opts.MaxThreads = 1
+	}
+
+	type candidate struct {
+		group Group
+		board Board
+		score float32
+	}
+	score := LogHeuristic(1)
+	candidates := []candidate{}
+	for _, group := range board.Groups() {
+		c := candidate{group: group, board: *board}
+		c.board.RemoveGroup(group.Mask)
+		c.board.Gravity()
+		c.score = score(&c.board)
+		candidates = append(candidates, c)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score < candidates[j].score
+	})
+
+	var mutex sync.Mutex
+	hints := []Hint{}
+
+	next := make(chan candidate)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.MaxThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range next {
+				result := SolveBeam(ctx, &c.board, BeamOptions{Width: opts.BeamWidth})
+				if result.Moves == nil {
+					continue
+				}
+				rest := result.Moves
+
+				hint := Hint{
+					Click:      c.group.Click,
+					Removed:    c.group,
+					Clicks:     len(rest) + 1,
+					LowerBound: c.board.lowerBound() + 1,
+					Solution:   append([]uint8{c.group.Click}, rest...),
+				}
+				mutex.Lock()
+				hints = append(hints, hint)
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for _, c := range candidates {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case next <- c:
+		case <-ctx.Done():
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	sort.SliceStable(hints, func(i, j int) bool {
+		if hints[i].Clicks != hints[j].Clicks {
+			return hints[i].Clicks < hints[j].Clicks
+		}
+		if hints[i].LowerBound != hints[j].LowerBound {
+			return hints[i].LowerBound < hints[j].LowerBound
+		}
+		// the hints are found in parallel, so keep the order stable
+		return hints[i].Click > hints[j].Click
+	})
+	if len(hints) > opts.Count {
+		hints = hints[:opts.Count]
+	}
+	return hints
+}
@@ -0,0 +1,44 @@
+package formerfast
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetHints(t *testing.T) {
+	board := testBoards(t)["25-11-2024.json"]
+	hints := GetHints(context.Background(), board, HintOptions{Count: 2, BeamWidth: 10})
+	if len(hints) != 2 {
+		t.Fatalf("%d hints, expected 2", len(hints))
+	}
+	for _, hint := range hints {
+		if verified, err := Verify(board, hint.Solution); err != nil || !verified.Cleared() {
+			t.Errorf("hint %d: solution does not clear the board: %v", hint.Click, err)
+		}
+		if hint.Clicks != len(hint.Solution) || hint.LowerBound > hint.Clicks {
+			t.Errorf("hint %d: %d clicks, lower bound %d, solution of %d", hint.Click, hint.Clicks, hint.LowerBound, len(hint.Solution))
+		}
+	}
+	if hints[0].Clicks > hints[1].Clicks {
+		t.Error("hints are not ordered best first")
+	}
+}
+
+func TestGetHintsStops(t *testing.T) {
+	board := testBoards(t)["25-11-2024.json"]
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// a beam this wide takes minutes for every click
+	start := time.Now()
+	GetHints(ctx, board, HintOptions{BeamWidth: 1000000})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("GetHints returned %v after the deadline", elapsed)
+	}
+
+	result := SolveBeam(ctx, board, BeamOptions{Width: 10})
+	if result.Complete || result.Moves != nil {
+		t.Error("beam search ran after the deadline")
+	}
+}
@@ -0,0 +1,175 @@
+package formerfast
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// SolveBoardUsingIDAStar finds a solution with iterative deepening A*.
+// It uses the same heuristic as SolveBoardUsingAStar, but instead of keeping
+// every frontier state in a priority queue it does a depth first search that
+// is cut off when the estimated solution length goes above a bound. The bound
+// is raised to the smallest estimate that was cut off, and the search starts
+// over. Only the current path is kept in memory, so this can run on boards
+// where A* runs out of memory, at the cost of expanding states many times.
+func SolveBoardUsingIDAStar(board *Board, heuristicTuning float32) []uint8 {
+	return SolveBoardUsingParallelIDAStar(board, 1, heuristicTuning)
+}
+
+// SolveBoardUsingParallelIDAStar is SolveBoardUsingIDAStar, but every
+// iteration splits the children of the root between maxThreads goroutines.
+func SolveBoardUsingParallelIDAStar(board *Board, maxThreads int, heuristicTuning float32) []uint8 {
+	return SolveWithIDAStar(board, maxThreads, LogHeuristic(heuristicTuning))
+}
+
+// SolveWithIDAStar is SolveBoardUsingParallelIDAStar with any heuristic. If
+// the heuristic never overestimates, the solution found is the shortest.
+func SolveWithIDAStar(board *Board, maxThreads int, heuristic Heuristic) []uint8 {
+	return idaStar(context.Background(), board, maxThreads, heuristic).Moves
+}
+
+type idaChild struct {
+	board    Board
+	pos      uint8
+	estimate float32
+}
+
+// expandForIDA returns the children of board, with the most promising first
+// so a solution is found early in the last iteration
+func expandForIDA(board *Board, estimate Heuristic) []idaChild {
+	possibleClicks := board.GetPossibleClicks()
+	children := make([]idaChild, len(possibleClicks))
+
+	for i, pos := range possibleClicks {
+		children[i].board = *board
+		children[i].board.RemoveBricksIterative(pos)
+		children[i].board.Gravity()
+		children[i].pos = pos
+		if children[i].board.isBoardEmpty() {
+			children[i].estimate = 0
+		} else {
+			children[i].estimate = estimate(&children[i].board)
+		}
+	}
+
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].estimate < children[j].estimate
+	})
+	return children
+}
+
+type idaSearch struct {
+	estimate  Heuristic
+	path      []uint8
+	stop      *atomic.Bool
+	cancelled *atomic.Bool
+}
+
+// search looks for a solution below board within bound. If it finds one the
+// clicks are left in s.path, otherwise it returns the smallest estimate that
+// went over the bound.
+func (s *idaSearch) search(board *Board, estimate float32, bound float32) (float32, bool) {
+	f := float32(len(s.path)) + estimate
+	if f > bound {
+		return f, false
+	}
+	if board.isBoardEmpty() {
+		return f, true
+	}
+	if s.stop.Load() || s.cancelled.Load() {
+		return float32(math.Inf(1)), false
+	}
+
+	nextBound := float32(math.Inf(1))
+	for _, child := range expandForIDA(board, s.estimate) {
+		s.path = append(s.path, child.pos)
+
+		t, found := s.search(&child.board, child.estimate, bound)
+		if found {
+			return t, true
+		}
+
+		s.path = s.path[:len(s.path)-1]
+		if t < nextBound {
+			nextBound = t
+		}
+	}
+	return nextBound, false
+}
+
+// idaStar stops when ctx is done, and then returns a result that is not
+// Complete and has no moves
+func idaStar(ctx context.Context, board *Board, maxThreads int, estimate Heuristic) SolveResult {
+	if board.isBoardEmpty() {
+		return SolveResult{Moves: []uint8{}, Complete: true}
+	}
+	if maxThreads < 1 {
+		maxThreads = 1
+	}
+
+	var cancelled atomic.Bool
+	stopWatching := context.AfterFunc(ctx, func() {
+		cancelled.Store(true)
+	})
+	defer stopWatching()
+
+	root := board.Copy()
+	children := expandForIDA(root, estimate)
+	bound := estimate(root)
+
+	for {
+		var stop atomic.Bool
+		var mutex sync.Mutex
+		var solution []uint8
+		nextBound := float32(math.Inf(1))
+
+		jobs := make(chan idaChild, len(children))
+		for _, child := range children {
+			jobs <- child
+		}
+		close(jobs)
+
+		var wg sync.WaitGroup
+		for i := 0; i < maxThreads; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s := &idaSearch{estimate: estimate, stop: &stop, cancelled: &cancelled}
+
+				for child := range jobs {
+					s.path = append(s.path[:0], child.pos)
+					t, found := s.search(&child.board, child.estimate, bound)
+
+					mutex.Lock()
+					if found {
+						if solution == nil || len(s.path) < len(solution) {
+							solution = append([]uint8{}, s.path...)
+						}
+						stop.Store(true)
+					} else if t < nextBound {
+						nextBound = t
+					}
+					mutex.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if solution != nil {
+			return SolveResult{Moves: solution, Complete: true}
+		}
+		// a search that was cancelled may have skipped the solution, so
+		// nothing is known
+		if cancelled.Load() {
+			return SolveResult{}
+		}
+		// every path has been cut off and there was nothing left to try
+		if math.IsInf(float64(nextBound), 1) {
+			return SolveResult{Complete: true}
+		}
+		bound = nextBound
+	}
+}
@@ -2,6 +2,7 @@ package formerfast
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/martcl/nrk-former/pkg/former"
 )
@@ -13,7 +14,13 @@ func LoadBoard(jsonData string) (*Board, error) {
 		return nil, err
 	}
 
-	board := &Board{}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("board has no rows")
+	}
+	board, err := NewBoard(len(data[0]), len(data))
+	if err != nil {
+		return nil, err
+	}
 
 	gemColorToType := map[string]BrickType{
 		"sirkel":   pink,
@@ -23,15 +30,17 @@ func LoadBoard(jsonData string) (*Board, error) {
 	}
 
 	for y, row := range data {
+		if len(row) != len(data[0]) {
+			return nil, fmt.Errorf("row %d has %d cells, expected %d", y, len(row), len(data[0]))
+		}
 		for x, gem := range row {
 			if gem.IsEmpty {
 				continue
 			}
 			brickType := gemColorToType[gem.GemColor]
-			pos := uint8(y*7 + x)
-
-			mask := uint64(1) << pos
-			board.State[brickType] |= mask
+			if err := board.SetBrick(board.Pos(x, y), brickType); err != nil {
+				return nil, err
+			}
 		}
 	}
 
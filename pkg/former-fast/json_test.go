@@ -0,0 +1,18 @@
+package formerfast
+
+import (
+	"testing"
+)
+
+func TestLoadBoardErrors(t *testing.T) {
+	gem := `{"gemColor": "pil", "isEmpty": false}`
+	for name, data := range map[string]string{
+		"no rows":     `[]`,
+		"ragged rows": `[[` + gem + `, ` + gem + `], [` + gem + `]]`,
+		"long row":    `[[` + gem + `], [` + gem + `, ` + gem + `]]`,
+	} {
+		if _, err := LoadBoard(data); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
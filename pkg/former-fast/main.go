@@ -1,9 +1,8 @@
 package formerfast
 
 import (
-	"encoding/binary"
 	"fmt"
-	"hash/fnv"
+	"math/bits"
 	"math/rand"
 )
 
@@ -18,193 +17,424 @@ const (
 
 type BrickType = uint8
 
-// We store the board state for each color in a uint64 since
-// we know that the board is always 7*9 = 63 size. If a bit
-// in the board is 0, there is not that color there and if the
-// bit is 1 there is that color there. If nether one of the colors
-// have a 1 bit in that position, then there is nothing there.
+// The brick types under exported names, for code outside the package
+const (
+	Orange BrickType = orange
+	Green  BrickType = green
+	Pink   BrickType = pink
+	Blue   BrickType = blue
+	Empty  BrickType = empty
+)
+
+// We store the board state for each color in a single uint64, with
+// one bit per cell. The daily board is 7*9 = 63 cells, which fits.
+// If a bit in the board is 0, there is not that color there and
+// if the bit is 1 there is that color there. If nether one of the
+// colors have a 1 bit in that position, then there is nothing there.
+//
+// Other sizes up to MaxCells are supported. Boards over 64 cells keep the
+// cells from 64 up in extra, so State only holds the first 64 of them.
+//
+// The board also keeps a Zobrist hash of the state, which is updated as
+// bricks are removed and fall, see Hash.
 type Board struct {
 	State [4]uint64
+	extra *boardExtra // nil means the 7x9 daily board
+	hash  uint64
+}
+
+// boardExtra is what a board needs on top of State when it is not the 7x9
+// daily board. It is shared between boards and never changed, a board whose
+// second words change gets a new one.
+type boardExtra struct {
+	layout *layout
+	hi     [4]uint64 // the cells from 64 up, zero on boards of 64 cells or fewer
+}
+
+// NewBoard creates an empty board with the given size
+func NewBoard(width int, height int) (*Board, error) {
+	l, err := getLayout(width, height)
+	if err != nil {
+		return nil, err
+	}
+	if l == defaultLayout {
+		return &Board{}, nil
+	}
+	return &Board{extra: l.extra}, nil
+}
+
+func (b *Board) geometry() *layout {
+	if b.extra == nil {
+		return defaultLayout
+	}
+	return b.extra.layout
+}
+
+// plane returns the cells of color c
+func (b *Board) plane(c int) Plane {
+	p := Plane{lo: b.State[c]}
+	if b.extra != nil {
+		p.hi = b.extra.hi[c]
+	}
+	return p
+}
+
+func (b *Board) planes() [4]Plane {
+	return [4]Plane{b.plane(orange), b.plane(green), b.plane(pink), b.plane(blue)}
+}
+
+// setPlanes replaces the cells of every color and updates the hash
+func (b *Board) setPlanes(planes [4]Plane) {
+	hi := [4]uint64{}
+	for c := orange; c <= blue; c++ {
+		b.hash ^= zobristOf(c, b.plane(c).xor(planes[c]))
+		b.State[c] = planes[c].lo
+		hi[c] = planes[c].hi
+	}
+	if b.extra != nil && b.extra.hi != hi {
+		b.extra = &boardExtra{layout: b.extra.layout, hi: hi}
+	}
+}
+
+func (b *Board) has(c int, pos uint8) bool {
+	if pos >= 64 {
+		return b.extra != nil && b.extra.hi[c]&(uint64(1)<<(pos-64)) != 0
+	}
+	return b.State[c]&(uint64(1)<<pos) != 0
 }
 
-// the pos is a single uint64 type and we use that to find out
+// Equal reports whether the boards have the same size and bricks
+func (b *Board) Equal(other *Board) bool {
+	return b.geometry() == other.geometry() && b.planes() == other.planes()
+}
+
+func (b *Board) Width() int { return b.geometry().width }
+
+func (b *Board) Height() int { return b.geometry().height }
+
+// Pos returns the position of the cell at (x, y)
+func (b *Board) Pos(x int, y int) uint8 {
+	return uint8(y*b.geometry().width + x)
+}
+
+// XY returns the column and row of pos
+func (b *Board) XY(pos uint8) (int, int) {
+	width := b.geometry().width
+	return int(pos) % width, int(pos) / width
+}
+
+// the pos is a single bit in the planes and we use that to find out
 // witch color is there
 func (b *Board) GetBrick(pos uint8) (BrickType, error) {
-	if pos >= 63 {
+	if int(pos) >= b.geometry().width*b.geometry().height {
 		return empty, fmt.Errorf("position out of bounds")
 	}
-	mask := uint64(1) << pos
-	if b.State[green]&mask != 0 {
+	if b.has(green, pos) {
 		return green, nil
-	} else if b.State[blue]&mask != 0 {
+	} else if b.has(blue, pos) {
 		return blue, nil
-	} else if b.State[orange]&mask != 0 {
+	} else if b.has(orange, pos) {
 		return orange, nil
-	} else if b.State[pink]&mask != 0 {
+	} else if b.has(pink, pos) {
 		return pink, nil
 	} else {
 		return empty, fmt.Errorf("no brick at position")
 	}
 }
 
-func (b *Board) RemoveBricksIterative(pos uint8) {
-	stack := []uint8{pos}
-	visited := make(map[uint8]bool)
-
-	brickType, _ := b.GetBrick(pos)
+// SetBrick puts a brick of the given color at pos, replacing what was there
+func (b *Board) SetBrick(pos uint8, brick BrickType) error {
+	if int(pos) >= b.geometry().width*b.geometry().height {
+		return fmt.Errorf("position out of bounds")
+	}
+	if brick > blue {
+		return fmt.Errorf("unknown brick type %d", brick)
+	}
+	planes := b.planes()
+	for c := range planes {
+		planes[c] = planes[c].without(pos)
+	}
+	planes[brick] = planes[brick].with(pos)
+	b.setPlanes(planes)
+	return nil
+}
 
-	for len(stack) > 0 {
-		pos = stack[len(stack)-1]
-		stack = stack[:len(stack)-1]
+// floodFill grows seed into the group of bricks in plane that are connected
+// to it. Instead of visiting one brick at a time it moves the whole group one
+// step in all four directions at once with shifts, and keeps the bits that
+// are in plane, until the group stops growing. The column masks stop bits
+// from wrapping around from one row to the next.
+func (l *layout) floodFill(seed Plane, plane Plane) Plane {
+	if l.small {
+		return Plane{lo: l.floodFillSmall(seed.lo, plane.lo)}
+	}
 
-		if visited[pos] {
-			continue
+	width := uint(l.width)
+	group := seed.and(plane)
+	for {
+		grown := group.
+			or(group.shiftUp(width)).              // down
+			or(group.shiftDown(width)).            // up
+			or(group.andNot(l.right).shiftUp(1)).  // right
+			or(group.andNot(l.left).shiftDown(1)). // left
+			and(plane)
+		if grown == group {
+			return group
 		}
+		group = grown
+	}
+}
 
-		brick, err := b.GetBrick(pos)
-		if err != nil || brick == empty || brick != brickType {
-			continue
+// floodFillSmall is floodFill for boards that fit in one word
+func (l *layout) floodFillSmall(seed uint64, plane uint64) uint64 {
+	width := uint(l.width)
+	left, right := l.left.lo, l.right.lo
+	group := seed & plane
+	for {
+		grown := (group | group<<width | group>>width | (group&^right)<<1 | (group&^left)>>1) & plane
+		if grown == group {
+			return group
 		}
+		group = grown
+	}
+}
 
-		visited[pos] = true
-
-		mask := uint64(1) << pos
-		switch brick {
-		case blue:
-			b.State[blue] &= ^mask
-		case green:
-			b.State[green] &= ^mask
-		case orange:
-			b.State[orange] &= ^mask
-		case pink:
-			b.State[pink] &= ^mask
-		}
+// ConnectedBricks returns a mask of the group of bricks at pos, or an empty
+// plane if the position is empty
+func (b *Board) ConnectedBricks(pos uint8) Plane {
+	brick, err := b.GetBrick(pos)
+	if err != nil {
+		return Plane{}
+	}
+	return b.geometry().floodFill(Plane{}.with(pos), b.plane(int(brick)))
+}
 
-		directions := []int64{-7, -1, 1, 7} // UP, LEFT, RIGHT, DOWN
-		for _, d := range directions {
-			neighbor := int64(pos) + d
-			if neighbor < 0 || neighbor >= 63 {
-				continue
-			}
-			if (pos%7 == 0 && d == -1) || (pos%7 == 6 && d == 1) {
-				continue
-			}
-			stack = append(stack, uint8(neighbor))
+func (b *Board) RemoveBricksIterative(pos uint8) {
+	b.RemoveGroup(b.ConnectedBricks(pos))
+}
+
+// RemoveGroup removes the bricks in mask, as returned by ConnectedBricks or
+// GetPossibleGroups
+func (b *Board) RemoveGroup(mask Plane) {
+	if b.geometry().small {
+		for c := orange; c <= blue; c++ {
+			b.hash ^= zobristOf(c, Plane{lo: b.State[c] & mask.lo})
+			b.State[c] &^= mask.lo
 		}
+		return
+	}
+
+	planes := b.planes()
+	for c := range planes {
+		planes[c] = planes[c].andNot(mask)
 	}
+	b.setPlanes(planes)
 }
 
-func (b *Board) GetPossibleClicks() []uint8 {
-	clicks := []uint8{}
-	visited := make(map[uint8]bool)
+// forEachGroup calls fn with the mask of every group on the board, ordered
+// from the highest position down by the highest position in the group
+func (b *Board) forEachGroup(fn func(representative uint8, group Plane)) {
+	l := b.geometry()
+	if l.small {
+		b.forEachGroupSmall(l, fn)
+		return
+	}
 
-	for pos := uint8(7*9) - 1; pos != 0; pos-- {
-		if visited[pos] {
-			continue
+	// the highest position of each group, across all colors
+	representatives := Plane{}
+	groups := [MaxCells]Plane{}
+	for c := orange; c <= blue; c++ {
+		remaining := b.plane(c)
+		for !remaining.isZero() {
+			pos := remaining.highest()
+			group := l.floodFill(Plane{}.with(pos), remaining)
+			remaining = remaining.andNot(group)
+			representatives = representatives.with(pos)
+			groups[pos] = group
 		}
+	}
 
-		brick, _ := b.GetBrick(pos)
+	for !representatives.isZero() {
+		pos := representatives.highest()
+		representatives = representatives.without(pos)
+		fn(pos, groups[pos])
+	}
+}
 
-		if brick == empty {
-			visited[pos] = true
-			continue
+// forEachGroupSmall is forEachGroup for boards that fit in one word
+func (b *Board) forEachGroupSmall(l *layout, fn func(representative uint8, group Plane)) {
+	representatives := uint64(0)
+	groups := [64]uint64{}
+	for c := orange; c <= blue; c++ {
+		remaining := b.State[c]
+		for remaining != 0 {
+			pos := 63 - bits.LeadingZeros64(remaining)
+			group := l.floodFillSmall(uint64(1)<<pos, remaining)
+			remaining &^= group
+			representatives |= uint64(1) << pos
+			groups[pos] = group
 		}
+	}
 
-		b.MarkConnectedBricks(pos, brick, visited)
-		clicks = append(clicks, pos)
+	for representatives != 0 {
+		pos := uint8(63 - bits.LeadingZeros64(representatives))
+		representatives &^= uint64(1) << pos
+		fn(pos, Plane{lo: groups[pos]})
 	}
+}
+
+// GetPossibleClicks returns one position in every group on the board
+func (b *Board) GetPossibleClicks() []uint8 {
+	clicks := make([]uint8, 0, 32)
+	b.forEachGroup(func(pos uint8, _ Plane) {
+		clicks = append(clicks, pos)
+	})
 	return clicks
 }
 
-func (b *Board) MarkConnectedBricks(pos uint8, brickType BrickType, visited map[uint8]bool) {
-	stack := []uint8{pos}
+// GetPossibleGroups returns the mask of every group on the board, in the same
+// order as GetPossibleClicks
+func (b *Board) GetPossibleGroups() []Plane {
+	groups := make([]Plane, 0, 32)
+	b.forEachGroup(func(_ uint8, group Plane) {
+		groups = append(groups, group)
+	})
+	return groups
+}
 
-	for len(stack) > 0 {
-		pos = stack[len(stack)-1]
-		stack = stack[:len(stack)-1]
+// Group describes one group of connected bricks with the same color
+type Group struct {
+	Click uint8 // the highest position in the group, the one GetPossibleClicks returns
+	Color BrickType
+	Size  int   // number of bricks in the group
+	Mask  Plane // every brick in the group
+}
 
-		if visited[pos] {
-			continue
-		}
+// Groups returns every group on the board, in the same order as
+// GetPossibleClicks
+func (b *Board) Groups() []Group {
+	groups := make([]Group, 0, 32)
+	b.forEachGroup(func(pos uint8, mask Plane) {
+		color, _ := b.GetBrick(pos)
+		groups = append(groups, Group{
+			Click: pos,
+			Color: color,
+			Size:  mask.onesCount(),
+			Mask:  mask,
+		})
+	})
+	return groups
+}
 
-		brick, err := b.GetBrick(pos)
-		if err != nil || brick == empty || brick != brickType {
+// CountGroups returns the number of groups on the board
+func (b *Board) CountGroups() int {
+	l := b.geometry()
+	count := 0
+	for c := orange; c <= blue; c++ {
+		if l.small {
+			remaining := b.State[c]
+			for remaining != 0 {
+				remaining &^= l.floodFillSmall(remaining&-remaining, remaining)
+				count++
+			}
 			continue
 		}
 
-		visited[pos] = true
-
-		directions := []int64{-7, -1, 1, 7} // UP, LEFT, RIGHT, DOWN
-		for _, d := range directions {
-			neighbor := int64(pos) + d
-			if neighbor < 0 || neighbor >= 63 {
-				continue
-			}
-			if (pos%7 == 0 && d == -1) || (pos%7 == 6 && d == 1) {
-				continue
-			}
-			stack = append(stack, uint8(neighbor))
+		remaining := b.plane(c)
+		for !remaining.isZero() {
+			seed := Plane{}.with(remaining.lowest())
+			remaining = remaining.andNot(l.floodFill(seed, remaining))
+			count++
 		}
 	}
+	return count
 }
 
-// TODO optemize this
+// Gravity makes every brick fall until it lands on another brick or the
+// bottom of the board. It works on all columns and colors at once: on each
+// step, every brick with an empty cell anywhere below it moves one row down.
+// A brick never has more empty cells below it than there are rows, so there
+// are at most height - 1 steps of a handful of bit operations each.
 func (b *Board) Gravity() {
-	for x := 0; x < 7; x++ {
-		// Collect bricks in the column
-		stack := []BrickType{}
-		for y := 0; y < 9; y++ {
-			pos := uint8(y*7 + x)
-			brick, err := b.GetBrick(pos)
-			if err == nil && brick != empty {
-				stack = append(stack, brick)
-			}
+	l := b.geometry()
+	if !l.small {
+		planes := b.planes()
+		gravityWide(l, &planes)
+		b.setPlanes(planes)
+		return
+	}
+
+	before := b.State
+	b.gravitySmall(l)
+
+	// only the cells a brick left or landed in change the hash
+	for c := orange; c <= blue; c++ {
+		b.hash ^= zobristOf(c, Plane{lo: before[c] ^ b.State[c]})
+	}
+}
+
+func gravityWide(l *layout, planes *[4]Plane) {
+	width := uint(l.width)
+	cells := uint(l.width * l.height)
+
+	occupied := planes[orange].or(planes[green]).or(planes[pink]).or(planes[blue])
+	for {
+		// spread every empty cell up its column, so a cell is
+		// set if it or any cell below it is empty
+		empty := l.all.andNot(occupied)
+		for shift := width; shift < cells; shift *= 2 {
+			empty = empty.or(empty.shiftDown(shift))
 		}
 
-		// Clear the column
-		for y := 0; y < 9; y++ {
-			pos := uint8(y*7 + x)
-			mask := uint64(1) << pos
-			for c := orange; c <= blue; c++ {
-				b.State[c] &= ^mask
-			}
+		falling := occupied.and(empty.shiftDown(width))
+		if falling.isZero() {
+			return
 		}
 
-		// Place bricks from the bottom up
-		yIndex := 8
-		for i := len(stack) - 1; i >= 0; i-- {
-			pos := uint8(yIndex*7 + x)
-			brick := stack[i]
-			mask := uint64(1) << pos
-			b.State[brick] |= mask
-			yIndex--
+		for c := orange; c <= blue; c++ {
+			moving := planes[c].and(falling)
+			planes[c] = planes[c].andNot(moving).or(moving.shiftUp(width))
 		}
+		occupied = occupied.andNot(falling).or(falling.shiftUp(width))
 	}
 }
 
-// Hash generates a unique hash for the current board state.
-// It includes all 64 bits of each uint64 in the State array.
-func (b *Board) Hash() uint32 {
-	h := fnv.New32a()
+// gravitySmall is Gravity for boards that fit in one word
+func (b *Board) gravitySmall(l *layout) {
+	width := uint(l.width)
+	cells := uint(l.width * l.height)
 
-	buf := make([]byte, 0, 32)
+	occupied := b.State[orange] | b.State[green] | b.State[pink] | b.State[blue]
+	for {
+		empty := l.all.lo &^ occupied
+		for shift := width; shift < cells; shift *= 2 {
+			empty |= empty >> shift
+		}
 
-	for _, state := range b.State {
-		temp := make([]byte, 8)
-		binary.LittleEndian.PutUint64(temp, state)
-		buf = append(buf, temp...)
-	}
+		falling := occupied & (empty >> width)
+		if falling == 0 {
+			return
+		}
 
-	h.Write(buf)
+		for c := orange; c <= blue; c++ {
+			moving := b.State[c] & falling
+			b.State[c] = b.State[c]&^moving | moving<<width
+		}
+		occupied = occupied&^falling | falling<<width
+	}
+}
 
-	return h.Sum32()
+// Hash returns a 64 bit Zobrist hash of the board state. It is kept up to
+// date by SetBrick, RemoveGroup and Gravity, so it costs nothing to read.
+// Different boards can in rare cases get the same hash, so use the full
+// State where a mix-up would give a wrong answer.
+func (b *Board) Hash() uint64 {
+	return b.hash
 }
 
 func (b *Board) Copy() *Board {
-	newBoard := &Board{
-		State: b.State,
-	}
-	return newBoard
+	newBoard := *b
+	return &newBoard
 }
 
 func (b *Board) PrintBoard() {
@@ -215,9 +445,9 @@ func (b *Board) PrintBoard() {
 		pink:   "P",
 		blue:   "B",
 	}
-	for y := 0; y < 9; y++ {
-		for x := 0; x < 7; x++ {
-			pos := uint8(y*7 + x)
+	for y := 0; y < b.Height(); y++ {
+		for x := 0; x < b.Width(); x++ {
+			pos := b.Pos(x, y)
 			brick, err := b.GetBrick(pos)
 			if err == nil && brick != empty {
 				fmt.Printf("%s ", brickSymbols[brick])
@@ -231,18 +461,21 @@ func (b *Board) PrintBoard() {
 }
 
 func CreateRadomBoard(height int, width int) (*Board, error) {
-	bricks := [4]uint64{}
+	board, err := NewBoard(width, height)
+	if err != nil {
+		return nil, err
+	}
 
 	for pos := 0; pos < height*width; pos++ {
-		color := rand.Intn(4)
-		bricks[color] |= uint64(1) << pos
+		board.SetBrick(uint8(pos), BrickType(rand.Intn(4)))
 	}
 
-	return &Board{
-		State: bricks,
-	}, nil
+	return board, nil
 }
 
 func (board *Board) isBoardEmpty() bool {
-	return board.State[orange]|board.State[green]|board.State[pink]|board.State[blue] == 0
+	if board.State[orange]|board.State[green]|board.State[pink]|board.State[blue] != 0 {
+		return false
+	}
+	return board.extra == nil || board.extra.hi == [4]uint64{}
 }
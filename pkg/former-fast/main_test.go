@@ -0,0 +1,198 @@
+package formerfast
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// board sizes the tests run on, from one column to a full MaxCells
+var testSizes = [][2]int{
+	{7, 9}, {1, 1}, {1, 100}, {3, 40}, {5, 5}, {8, 8}, {9, 7},
+	{10, 12}, {11, 11}, {16, 8}, {12, 10}, {64, 2}, {8, 16},
+}
+
+// grid is the reference the bitboards are checked against, one brick type
+// per cell with empty for no brick
+type grid struct {
+	width  int
+	height int
+	cells  []BrickType
+}
+
+func gridOf(b *Board) grid {
+	g := grid{width: b.Width(), height: b.Height(), cells: make([]BrickType, b.Width()*b.Height())}
+	for pos := range g.cells {
+		brick, err := b.GetBrick(uint8(pos))
+		if err != nil {
+			brick = empty
+		}
+		g.cells[pos] = brick
+	}
+	return g
+}
+
+// gravity lets the bricks fall one column at a time, like the Gravity
+// in the former package
+func (g grid) gravity() {
+	for x := 0; x < g.width; x++ {
+		stack := []BrickType{}
+		for y := 0; y < g.height; y++ {
+			if brick := g.cells[y*g.width+x]; brick != empty {
+				stack = append(stack, brick)
+			}
+		}
+		for y := g.height - 1; y >= 0; y-- {
+			brick := BrickType(empty)
+			if len(stack) > 0 {
+				brick = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+			g.cells[y*g.width+x] = brick
+		}
+	}
+}
+
+// remove removes the group at pos by visiting one cell at a time
+func (g grid) remove(pos int) {
+	brick := g.cells[pos]
+	if brick == empty {
+		return
+	}
+	stack := []int{pos}
+	g.cells[pos] = empty
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		x, y := p%g.width, p/g.width
+		for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			nx, ny := x+d[0], y+d[1]
+			if nx < 0 || nx >= g.width || ny < 0 || ny >= g.height {
+				continue
+			}
+			if n := ny*g.width + nx; g.cells[n] == brick {
+				g.cells[n] = empty
+				stack = append(stack, n)
+			}
+		}
+	}
+}
+
+// groups counts the groups by removing them one at a time from a copy
+func (g grid) groups() int {
+	c := grid{g.width, g.height, append([]BrickType{}, g.cells...)}
+	count := 0
+	for pos, brick := range c.cells {
+		if brick != empty {
+			c.remove(pos)
+			count++
+		}
+	}
+	return count
+}
+
+func (g grid) equal(other grid) bool {
+	for pos := range g.cells {
+		if g.cells[pos] != other.cells[pos] {
+			return false
+		}
+	}
+	return true
+}
+
+// randomBoard fills a board with two or four colors, and leaves some cells
+// empty so there is something to fall
+func randomBoard(t testing.TB, r *rand.Rand, width int, height int) *Board {
+	board, err := NewBoard(width, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	colors := 2 + 2*r.Intn(2)
+	for pos := 0; pos < width*height; pos++ {
+		if r.Intn(5) > 0 {
+			board.SetBrick(uint8(pos), BrickType(r.Intn(colors)))
+		}
+	}
+	return board
+}
+
+func checkHash(t *testing.T, board *Board) {
+	t.Helper()
+	rehashed := *board
+	rehashed.Rehash()
+	if rehashed.Hash() != board.Hash() {
+		t.Fatalf("hash %x, but %x when computed from scratch", board.Hash(), rehashed.Hash())
+	}
+}
+
+func TestGravityMatchesReference(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, size := range testSizes {
+		for i := 0; i < 2000; i++ {
+			board := randomBoard(t, r, size[0], size[1])
+			want := gridOf(board)
+			want.gravity()
+
+			board.Gravity()
+			if !gridOf(board).equal(want) {
+				t.Fatalf("%dx%d: gravity differs from the reference", size[0], size[1])
+			}
+			checkHash(t, board)
+		}
+	}
+}
+
+func TestClicksMatchReference(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for _, size := range testSizes {
+		for i := 0; i < 200; i++ {
+			board := randomBoard(t, r, size[0], size[1])
+			board.Gravity()
+			want := gridOf(board)
+
+			for !board.isBoardEmpty() {
+				if got := board.CountGroups(); got != want.groups() {
+					t.Fatalf("%dx%d: %d groups, reference has %d", size[0], size[1], got, want.groups())
+				}
+				clicks := board.GetPossibleClicks()
+				if len(clicks) != want.groups() {
+					t.Fatalf("%dx%d: %d clicks, reference has %d groups", size[0], size[1], len(clicks), want.groups())
+				}
+
+				// copies share the words of big boards, so clicking
+				// must leave the copy as it was
+				before, beforeGrid := *board, gridOf(board)
+
+				pos := clicks[r.Intn(len(clicks))]
+				want.remove(int(pos))
+				want.gravity()
+				board.RemoveBricksIterative(pos)
+				board.Gravity()
+
+				if !gridOf(board).equal(want) {
+					t.Fatalf("%dx%d: board after clicking %d differs from the reference", size[0], size[1], pos)
+				}
+				if !gridOf(&before).equal(beforeGrid) {
+					t.Fatalf("%dx%d: clicking %d changed a copy of the board", size[0], size[1], pos)
+				}
+				checkHash(t, board)
+			}
+			if board.Hash() != 0 {
+				t.Fatalf("%dx%d: empty board has hash %x", size[0], size[1], board.Hash())
+			}
+		}
+	}
+}
+
+func BenchmarkGravity(b *testing.B) {
+	r := rand.New(rand.NewSource(3))
+	boards := make([]Board, 256)
+	for i := range boards {
+		boards[i] = *randomBoard(b, r, 7, 9)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		board := boards[i%len(boards)]
+		board.Gravity()
+	}
+}
@@ -0,0 +1,207 @@
+package formerfast
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+type NRPAOptions struct {
+	Level      int     // nesting level, 2 if 0
+	Iterations int     // iterations on each level, 100 if 0
+	Alpha      float64 // how far the policy moves towards the best sequence, 1 if 0
+	MaxThreads int     // independent searches run at once, at least 1
+	Seed       int64   // seed for the random playouts
+	// searches each goroutine runs when ctx can never be done, like
+	// context.Background(), 1 if 0
+	Searches int
+}
+
+// SolveBoardUsingNRPA searches with Nested Rollout Policy Adaptation until ctx
+// is done, and returns the shortest solution it found.
+//
+// A playout clicks random groups until the board is clear, picking each
+// click with a probability given by a policy. The policy learns from the best
+// playout so far on each nesting level, so the playouts get better over time.
+// Unlike A*, NRPA is happy to try a click that looks bad now, which helps on
+// boards where an early sacrifice pays off later.
+//
+// When a search finishes before ctx is done, a new one is started, and each
+// of the MaxThreads goroutines runs its own searches. If ctx can never be
+// done, each goroutine stops after Searches searches instead.
+func SolveBoardUsingNRPA(ctx context.Context, board *Board, opts NRPAOptions) []uint8 {
+	if opts.Level < 1 {
+		opts.Level = 2
+	}
+	if opts.Iterations < 1 {
+		opts.Iterations = 100
+	}
+	if opts.Alpha == 0 {
+		opts.Alpha = 1
+	}
+	if opts.MaxThreads < 1 {
+		opts.MaxThreads = 1
+	}
+	if opts.Searches < 1 {
+		opts.Searches = 1
+	}
+
+	if board.isBoardEmpty() {
+		return []uint8{}
+	}
+	// without a way to stop, the searches would go on forever
+	bounded := ctx.Done() == nil
+
+	var mutex sync.Mutex
+	var best []uint8
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.MaxThreads; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			n := &nrpaSearch{
+				ctx:    ctx,
+				root:   *board,
+				opts:   opts,
+				random: rand.New(rand.NewSource(seed)),
+			}
+			for searches := 0; ctx.Err() == nil; searches++ {
+				if bounded && searches == opts.Searches {
+					break
+				}
+				result := n.nested(opts.Level, make(nrpaPolicy, nrpaPolicySize))
+
+				mutex.Lock()
+				if result.moves != nil && (best == nil || len(result.moves) < len(best)) {
+					best = result.moves
+				}
+				mutex.Unlock()
+			}
+		}(opts.Seed + int64(i))
+	}
+	wg.Wait()
+
+	return best
+}
+
+// a click is coded by the color and position of the group's representative
+const nrpaPolicySize = MaxCells * 4
+
+type nrpaPolicy []float64
+
+func nrpaCode(board *Board, pos uint8) int {
+	brick, _ := board.GetBrick(pos)
+	return int(pos)*4 + int(brick)
+}
+
+type nrpaResult struct {
+	moves []uint8 // nil if the playout was stopped before the board was clear
+}
+
+func (r nrpaResult) betterOrEqual(other nrpaResult) bool {
+	if r.moves == nil {
+		return other.moves == nil
+	}
+	return other.moves == nil || len(r.moves) <= len(other.moves)
+}
+
+type nrpaSearch struct {
+	ctx    context.Context
+	root   Board
+	opts   NRPAOptions
+	random *rand.Rand
+	// reused between playouts
+	weights []float64
+}
+
+func (n *nrpaSearch) nested(level int, policy nrpaPolicy) nrpaResult {
+	if level == 0 {
+		return n.playout(policy)
+	}
+
+	best := nrpaResult{}
+	for i := 0; i < n.opts.Iterations; i++ {
+		if n.ctx.Err() != nil {
+			break
+		}
+		child := append(nrpaPolicy{}, policy...)
+		result := n.nested(level-1, child)
+		if result.betterOrEqual(best) {
+			best = result
+		}
+		if best.moves != nil {
+			policy = n.adapt(policy, best.moves)
+		}
+	}
+	return best
+}
+
+// playout clicks until the board is clear, picking clicks at random with
+// probability proportional to exp(policy)
+func (n *nrpaSearch) playout(policy nrpaPolicy) nrpaResult {
+	board := n.root
+	moves := []uint8{}
+
+	for !board.isBoardEmpty() {
+		if n.ctx.Err() != nil {
+			return nrpaResult{}
+		}
+
+		possibleClicks := board.GetPossibleClicks()
+		if len(possibleClicks) == 0 {
+			return nrpaResult{}
+		}
+
+		n.weights = n.weights[:0]
+		total := 0.0
+		for _, pos := range possibleClicks {
+			w := math.Exp(policy[nrpaCode(&board, pos)])
+			n.weights = append(n.weights, w)
+			total += w
+		}
+
+		pick := len(possibleClicks) - 1
+		r := n.random.Float64() * total
+		for i, w := range n.weights {
+			r -= w
+			if r < 0 {
+				pick = i
+				break
+			}
+		}
+
+		pos := possibleClicks[pick]
+		moves = append(moves, pos)
+		board.RemoveBricksIterative(pos)
+		board.Gravity()
+	}
+	return nrpaResult{moves: moves}
+}
+
+// adapt returns a copy of policy that makes the clicks in moves more likely,
+// and the other clicks that were possible along the way less likely
+func (n *nrpaSearch) adapt(policy nrpaPolicy, moves []uint8) nrpaPolicy {
+	adapted := append(nrpaPolicy{}, policy...)
+	board := n.root
+
+	for _, pos := range moves {
+		possibleClicks := board.GetPossibleClicks()
+
+		total := 0.0
+		for _, p := range possibleClicks {
+			total += math.Exp(policy[nrpaCode(&board, p)])
+		}
+
+		adapted[nrpaCode(&board, pos)] += n.opts.Alpha
+		for _, p := range possibleClicks {
+			code := nrpaCode(&board, p)
+			adapted[code] -= n.opts.Alpha * math.Exp(policy[code]) / total
+		}
+
+		board.RemoveBricksIterative(pos)
+		board.Gravity()
+	}
+	return adapted
+}
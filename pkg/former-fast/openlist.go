@@ -0,0 +1,136 @@
+package formerfast
+
+import (
+	"container/heap"
+)
+
+// OpenList holds the states waiting to be expanded by a search worker, and
+// hands them out lowest Priority first.
+type OpenList interface {
+	Push(state *State)
+	Pop() *State // nil when the list is empty
+	Len() int
+}
+
+// HeapOpenList is an OpenList backed by a binary heap on PriorityQueue.
+// Push and Pop are O(log n) and priorities are compared exactly.
+type HeapOpenList struct {
+	pq PriorityQueue
+}
+
+func NewHeapOpenList() OpenList {
+	return &HeapOpenList{}
+}
+
+func (h *HeapOpenList) Push(state *State) { heap.Push(&h.pq, state) }
+
+func (h *HeapOpenList) Pop() *State {
+	if len(h.pq) == 0 {
+		return nil
+	}
+	return heap.Pop(&h.pq).(*State)
+}
+
+func (h *HeapOpenList) Len() int { return len(h.pq) }
+
+// BucketOpenList is an OpenList that rounds priorities down to steps of
+// 1/resolution and keeps one bucket per step. Within a bucket, the states
+// with the most clicks are popped first, and the most recently pushed first
+// among those, which dives towards a solution when many states are tied.
+// Push and Pop are O(1) apart from skipping empty buckets, but states whose
+// priority rounds to the same step are not ordered exactly.
+type BucketOpenList struct {
+	resolution float32
+	buckets    []priorityBucket
+	lowest     int // no bucket below this has states
+	size       int
+}
+
+type priorityBucket struct {
+	byClicks [][]*State // stack of states for each number of clicks
+	size     int
+	deepest  int // no stack above this has states
+}
+
+// NewBucketOpenList creates a bucket queue with resolution buckets per click
+func NewBucketOpenList(resolution float32) OpenList {
+	if resolution <= 0 {
+		resolution = 1
+	}
+	return &BucketOpenList{resolution: resolution}
+}
+
+func (b *BucketOpenList) Push(state *State) {
+	index := 0
+	if state.Priority > 0 {
+		index = int(state.Priority * b.resolution)
+	}
+	for index >= len(b.buckets) {
+		b.buckets = append(b.buckets, priorityBucket{})
+	}
+
+	bucket := &b.buckets[index]
+	clicks := state.Path.Len()
+	for clicks >= len(bucket.byClicks) {
+		bucket.byClicks = append(bucket.byClicks, nil)
+	}
+	bucket.byClicks[clicks] = append(bucket.byClicks[clicks], state)
+	bucket.size++
+	if clicks > bucket.deepest {
+		bucket.deepest = clicks
+	}
+
+	if b.size == 0 || index < b.lowest {
+		b.lowest = index
+	}
+	b.size++
+}
+
+func (b *BucketOpenList) Pop() *State {
+	if b.size == 0 {
+		return nil
+	}
+	for b.buckets[b.lowest].size == 0 {
+		b.lowest++
+	}
+
+	bucket := &b.buckets[b.lowest]
+	for len(bucket.byClicks[bucket.deepest]) == 0 {
+		bucket.deepest--
+	}
+
+	stack := bucket.byClicks[bucket.deepest]
+	state := stack[len(stack)-1]
+	stack[len(stack)-1] = nil
+	bucket.byClicks[bucket.deepest] = stack[:len(stack)-1]
+	bucket.size--
+	b.size--
+	return state
+}
+
+func (b *BucketOpenList) Len() int { return b.size }
+
+// CollectStates expands the board breadth first until it has at least count
+// states, with priorities like Solve gives them. It makes the same states to
+// push through each OpenList when comparing them.
+func CollectStates(board *Board, count int, heuristic Heuristic) []*State {
+	states := []*State{{Board: board.Copy()}}
+	for next := 0; len(states) < count && next < len(states); next++ {
+		state := states[next]
+		for _, pos := range state.Board.GetPossibleClicks() {
+			nextBoard := state.Board.Copy()
+			nextBoard.RemoveBricksIterative(pos)
+			nextBoard.Gravity()
+
+			nextPath := state.Path.Then(pos)
+			estimate := heuristic(nextBoard)
+			states = append(states, &State{
+				Board:    nextBoard,
+				Path:     nextPath,
+				Estimate: estimate,
+				Priority: float32(nextPath.Len()) + estimate,
+			})
+		}
+	}
+	return states
+}
@@ -0,0 +1,86 @@
+package formerfast
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testBoards loads the boards in the tests folder at the root of the repo
+func testBoards(tb testing.TB) map[string]*Board {
+	files, err := filepath.Glob("../../tests/*.json")
+	if err != nil || len(files) == 0 {
+		tb.Fatalf("no boards found in tests: %v", err)
+	}
+
+	boards := map[string]*Board{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		board, err := LoadBoard(string(data))
+		if err != nil {
+			tb.Fatalf("%s: %v", file, err)
+		}
+		boards[filepath.Base(file)] = board
+	}
+	return boards
+}
+
+func TestOpenLists(t *testing.T) {
+	for name, board := range testBoards(t) {
+		states := CollectStates(board, 20000, LogHeuristic(4.5))
+		for _, list := range []OpenList{NewHeapOpenList(), NewBucketOpenList(4)} {
+			for _, state := range states {
+				list.Push(state)
+			}
+			if list.Len() != len(states) {
+				t.Fatalf("%s: %d states in the list, pushed %d", name, list.Len(), len(states))
+			}
+
+			// the bucket list rounds priorities down to steps of 1/4
+			previous := float32(-1)
+			for i := range states {
+				state := list.Pop()
+				if state == nil {
+					t.Fatalf("%s: list empty after %d of %d states", name, i, len(states))
+				}
+				if state.Priority < previous-0.25 {
+					t.Fatalf("%s: popped priority %f after %f", name, state.Priority, previous)
+				}
+				previous = max(previous, state.Priority)
+			}
+			if list.Pop() != nil || list.Len() != 0 {
+				t.Fatalf("%s: list not empty after popping every state", name)
+			}
+		}
+	}
+}
+
+func benchmarkOpenList(b *testing.B, newOpenList func() OpenList) {
+	states := []*State{}
+	for _, board := range testBoards(b) {
+		states = append(states, CollectStates(board, 50000, LogHeuristic(4.5))...)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		list := newOpenList()
+		for _, state := range states {
+			list.Push(state)
+		}
+		for list.Len() > 0 {
+			list.Pop()
+		}
+	}
+	b.ReportMetric(float64(b.N*len(states))/b.Elapsed().Seconds(), "push+pop/s")
+}
+
+func BenchmarkOpenListHeap(b *testing.B) {
+	benchmarkOpenList(b, NewHeapOpenList)
+}
+
+func BenchmarkOpenListBucket(b *testing.B) {
+	benchmarkOpenList(b, func() OpenList { return NewBucketOpenList(4) })
+}
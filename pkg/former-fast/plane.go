@@ -0,0 +1,183 @@
+package formerfast
+
+import (
+	"fmt"
+	"math/bits"
+	"sync"
+)
+
+// MaxCells is the largest number of cells a board can have
+const MaxCells = 128
+
+// Plane has one bit for every cell on the board, bit y*width + x is the cell
+// at (x, y). Boards with more than 64 cells use the second word, the lowest
+// cells are in the first word. A struct of two words is used rather than an
+// array, since the compiler keeps small structs in registers. Boards only
+// store the second word when they have more than 64 cells, see Board.
+type Plane struct {
+	lo uint64
+	hi uint64
+}
+
+func (p Plane) and(q Plane) Plane {
+	return Plane{p.lo & q.lo, p.hi & q.hi}
+}
+
+func (p Plane) or(q Plane) Plane {
+	return Plane{p.lo | q.lo, p.hi | q.hi}
+}
+
+func (p Plane) andNot(q Plane) Plane {
+	return Plane{p.lo &^ q.lo, p.hi &^ q.hi}
+}
+
+func (p Plane) xor(q Plane) Plane {
+	return Plane{p.lo ^ q.lo, p.hi ^ q.hi}
+}
+
+func (p Plane) isZero() bool {
+	return p.lo|p.hi == 0
+}
+
+func (p Plane) has(pos uint8) bool {
+	if pos >= 64 {
+		return p.hi&(uint64(1)<<(pos-64)) != 0
+	}
+	return p.lo&(uint64(1)<<pos) != 0
+}
+
+func (p Plane) with(pos uint8) Plane {
+	if pos >= 64 {
+		p.hi |= uint64(1) << (pos - 64)
+	} else {
+		p.lo |= uint64(1) << pos
+	}
+	return p
+}
+
+func (p Plane) without(pos uint8) Plane {
+	if pos >= 64 {
+		p.hi &^= uint64(1) << (pos - 64)
+	} else {
+		p.lo &^= uint64(1) << pos
+	}
+	return p
+}
+
+// highest returns the highest set position, the plane must not be zero
+func (p Plane) highest() uint8 {
+	if p.hi != 0 {
+		return uint8(127 - bits.LeadingZeros64(p.hi))
+	}
+	return uint8(63 - bits.LeadingZeros64(p.lo))
+}
+
+// lowest returns the lowest set position, the plane must not be zero
+func (p Plane) lowest() uint8 {
+	if p.lo != 0 {
+		return uint8(bits.TrailingZeros64(p.lo))
+	}
+	return uint8(64 + bits.TrailingZeros64(p.hi))
+}
+
+func (p Plane) onesCount() int {
+	return bits.OnesCount64(p.lo) + bits.OnesCount64(p.hi)
+}
+
+// shiftUp moves every bit n positions higher, towards the bottom of the board
+func (p Plane) shiftUp(n uint) Plane {
+	if n >= 64 {
+		return Plane{0, p.lo << (n - 64)}
+	}
+	return Plane{p.lo << n, p.hi<<n | p.lo>>(64-n)}
+}
+
+// shiftDown moves every bit n positions lower, towards the top of the board
+func (p Plane) shiftDown(n uint) Plane {
+	if n >= 64 {
+		return Plane{p.hi >> (n - 64), 0}
+	}
+	return Plane{p.lo>>n | p.hi<<(64-n), p.hi >> n}
+}
+
+// Has reports whether the cell at pos is in the plane
+func (p Plane) Has(pos uint8) bool {
+	return pos < MaxCells && p.has(pos)
+}
+
+// Count returns the number of cells in the plane
+func (p Plane) Count() int {
+	return p.onesCount()
+}
+
+// Positions returns the cells in the plane from the lowest position up
+func (p Plane) Positions() []uint8 {
+	positions := make([]uint8, 0, p.onesCount())
+	for !p.isZero() {
+		pos := p.lowest()
+		positions = append(positions, pos)
+		p = p.without(pos)
+	}
+	return positions
+}
+
+// layout holds the masks for one board size, shared by all boards of that size
+type layout struct {
+	width   int
+	height  int
+	all     Plane       // every cell on the board
+	left    Plane       // the first column
+	right   Plane       // the last column
+	columns []Plane     // every column
+	small   bool        // the board fits in the first word
+	extra   *boardExtra // shared by the new boards of this size
+}
+
+var (
+	layoutsMutex sync.Mutex
+	layouts      = map[[2]int]*layout{}
+)
+
+// the layout of the daily NRK board, used by boards without one
+var defaultLayout = mustGetLayout(7, 9)
+
+func getLayout(width int, height int) (*layout, error) {
+	if width < 1 || height < 1 || width > 64 || width*height > MaxCells {
+		return nil, fmt.Errorf("board size %dx%d not supported, at most %d cells and 64 columns", width, height, MaxCells)
+	}
+
+	layoutsMutex.Lock()
+	defer layoutsMutex.Unlock()
+
+	if l, exists := layouts[[2]int{width, height}]; exists {
+		return l, nil
+	}
+
+	l := &layout{
+		width:   width,
+		height:  height,
+		columns: make([]Plane, width),
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pos := uint8(y*width + x)
+			l.all = l.all.with(pos)
+			l.columns[x] = l.columns[x].with(pos)
+		}
+	}
+	l.left = l.columns[0]
+	l.right = l.columns[width-1]
+	l.small = width*height <= 64
+	l.extra = &boardExtra{layout: l}
+
+	layouts[[2]int{width, height}] = l
+	return l, nil
+}
+
+func mustGetLayout(width int, height int) *layout {
+	l, err := getLayout(width, height)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
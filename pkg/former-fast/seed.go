@@ -0,0 +1,154 @@
+package formerfast
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FormatDate writes date using the year, month and day parts of a Go layout,
+// like "02012006". Unlike time.Format it can count months from 0, the way
+// getMonth does in JavaScript.
+func FormatDate(date time.Time, layout string, zeroMonth bool) string {
+	month := int(date.Month())
+	if zeroMonth {
+		month--
+	}
+
+	var b strings.Builder
+	for layout != "" {
+		switch {
+		case strings.HasPrefix(layout, "2006"):
+			fmt.Fprintf(&b, "%04d", date.Year())
+			layout = layout[4:]
+		case strings.HasPrefix(layout, "01"):
+			fmt.Fprintf(&b, "%02d", month)
+			layout = layout[2:]
+		case strings.HasPrefix(layout, "02"):
+			fmt.Fprintf(&b, "%02d", date.Day())
+			layout = layout[2:]
+		case strings.HasPrefix(layout, "1"):
+			fmt.Fprintf(&b, "%d", month)
+			layout = layout[1:]
+		case strings.HasPrefix(layout, "2"):
+			fmt.Fprintf(&b, "%d", date.Day())
+			layout = layout[1:]
+		default:
+			b.WriteByte(layout[0])
+			layout = layout[1:]
+		}
+	}
+	return b.String()
+}
+
+type SeedSearchOptions struct {
+	From       time.Time // first date tried
+	To         time.Time // last date tried
+	Formats    []string  // layouts for FormatDate, "02012006" if empty
+	ZeroMonth  bool      // also try every format with months counted from 0
+	Seeds      []string  // seed strings tried as they are, in addition to the dates
+	MaxThreads int       // at least 1
+}
+
+// SeedMatch is a seed that generates the board
+type SeedMatch struct {
+	Seed       string
+	Date       time.Time // the date the seed was made from, zero for one of Seeds
+	DateString string    // the date as formatted before it was hashed
+	Format     string
+	ZeroMonth  bool
+}
+
+// FindSeeds returns every seed that makes CreateBoardWithPseudoRandom generate
+// exactly board. For each date from From to To and each format, the seed is
+// the md5 of the formatted date, as in CreateBoardFromDate. The seeds in
+// Seeds are tried as well. Only a board that has not been played yet can
+// match.
+//
+// The matches are ordered by date, with the matches from Seeds first. If ctx
+// is done before every candidate is tried, the matches found so far are
+// returned together with the context's error.
+func FindSeeds(ctx context.Context, board *Board, opts SeedSearchOptions) ([]SeedMatch, error) {
+	if len(opts.Formats) == 0 {
+		opts.Formats = []string{"02012006"}
+	}
+	if opts.MaxThreads < 1 {
+		opts.MaxThreads = 1
+	}
+
+	candidates := make(chan SeedMatch)
+	go func() {
+		defer close(candidates)
+		send := func(candidate SeedMatch) bool {
+			select {
+			case candidates <- candidate:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, seed := range opts.Seeds {
+			if !send(SeedMatch{Seed: seed}) {
+				return
+			}
+		}
+
+		zeroMonths := []bool{false}
+		if opts.ZeroMonth {
+			zeroMonths = append(zeroMonths, true)
+		}
+		for date := opts.From; !date.After(opts.To); date = date.AddDate(0, 0, 1) {
+			for _, format := range opts.Formats {
+				for _, zeroMonth := range zeroMonths {
+					dateString := FormatDate(date, format, zeroMonth)
+					match := SeedMatch{
+						Seed:       MD5Seed(dateString),
+						Date:       date,
+						DateString: dateString,
+						Format:     format,
+						ZeroMonth:  zeroMonth,
+					}
+					if !send(match) {
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	var mutex sync.Mutex
+	matches := []SeedMatch{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.MaxThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for candidate := range candidates {
+				generated, err := CreateBoardWithPseudoRandom(board.Height(), board.Width(), InitializeRandomState(candidate.Seed))
+				if err != nil || !generated.Equal(board) {
+					continue
+				}
+				mutex.Lock()
+				matches = append(matches, candidate)
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if !matches[i].Date.Equal(matches[j].Date) {
+			return matches[i].Date.Before(matches[j].Date)
+		}
+		if matches[i].DateString != matches[j].DateString {
+			return matches[i].DateString < matches[j].DateString
+		}
+		return matches[i].Seed+matches[i].Format < matches[j].Seed+matches[j].Format
+	})
+	return matches, ctx.Err()
+}
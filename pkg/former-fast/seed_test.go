@@ -0,0 +1,41 @@
+package formerfast
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFindSeeds(t *testing.T) {
+	board := testBoards(t)["26-11-2024.json"]
+	matches, err := FindSeeds(context.Background(), board, SeedSearchOptions{
+		From:       time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2024, 11, 30, 0, 0, 0, 0, time.UTC),
+		ZeroMonth:  true,
+		Seeds:      []string{"cff00d616484462eb325f50a5c0cd6a3"},
+		MaxThreads: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 31 days before the board's date, or the date itself with the months
+	// counted from 0, and both are hashed from "26102024"
+	want := []SeedMatch{
+		{Date: time.Date(2024, 10, 26, 0, 0, 0, 0, time.UTC), Format: "02012006"},
+		{Date: time.Date(2024, 11, 26, 0, 0, 0, 0, time.UTC), Format: "02012006", ZeroMonth: true},
+	}
+	for i := range want {
+		want[i].DateString = "26102024"
+		want[i].Seed = MD5Seed("26102024")
+	}
+	if len(matches) != len(want) {
+		t.Fatalf("found %d seeds, expected %d: %+v", len(matches), len(want), matches)
+	}
+	for i := range want {
+		if !matches[i].Date.Equal(want[i].Date) || matches[i].DateString != want[i].DateString ||
+			matches[i].Seed != want[i].Seed || matches[i].Format != want[i].Format || matches[i].ZeroMonth != want[i].ZeroMonth {
+			t.Errorf("match %d is %+v, expected %+v", i, matches[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package formerfast
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+type SolveOptions struct {
+	MaxThreads      int     // number of worker goroutines, at least 1
+	HeuristicTuning float32 // see heuristic, higher is faster but less accurate
+	// estimates the clicks left, LogHeuristic(HeuristicTuning) if nil
+	Heuristic Heuristic
+	// creates the open list of each worker, NewHeapOpenList if nil
+	NewOpenList func() OpenList
+	// a solution that is already known, only shorter ones are searched for
+	Incumbent []uint8
+}
+
+type SolveResult struct {
+	Moves    []uint8 // best solution found, nil if none was found
+	Complete bool    // false if the search was stopped by the context
+	Stats    []WorkerStats
+}
+
+// WorkerStats tells how much of the search one worker did. Since states are
+// split between workers by their hash, the numbers should be about the same
+// for every worker.
+type WorkerStats struct {
+	Expanded   uint64 // states popped and expanded
+	Received   uint64 // states pushed to this worker, by itself or others
+	Duplicates uint64 // received states dropped since they were reached with fewer clicks before
+	Sent       uint64 // successors sent to other workers
+	MaxOpen    int    // largest size of the open list
+}
+
+// Solve finds a solution with A* like SolveBoardUsingAStar, but stops when
+// ctx is cancelled or its deadline passes. The workers are stopped before
+// Solve returns. If the search was stopped early, the result has Complete set
+// to false and holds the best solution found so far, if any.
+//
+// The search is hash distributed (HDA*). Every board state is owned by one
+// worker, picked by its hash, and only that worker keeps it in its open and
+// closed lists. New states are sent to their owner in batches, so workers
+// never share a queue or a lock on the hot path. Once a solution is found,
+// the workers keep expanding states that could lead to a shorter one, and
+// the search ends when no such states are left anywhere.
+func Solve(ctx context.Context, board *Board, opts SolveOptions) SolveResult {
+	if opts.MaxThreads < 1 {
+		opts.MaxThreads = 1
+	}
+	if opts.Heuristic == nil {
+		opts.Heuristic = LogHeuristic(opts.HeuristicTuning)
+	}
+	if opts.NewOpenList == nil {
+		opts.NewOpenList = NewHeapOpenList
+	}
+
+	s := &hdaSearch{
+		heuristic: opts.Heuristic,
+		workers:   make([]*hdaWorker, opts.MaxThreads),
+		done:      make(chan struct{}),
+	}
+	s.incumbent.Store(math.MaxInt32)
+	if opts.Incumbent != nil {
+		s.solution = opts.Incumbent
+		s.incumbent.Store(int32(len(opts.Incumbent)))
+	}
+	for i := range s.workers {
+		s.workers[i] = &hdaWorker{
+			id:      i,
+			search:  s,
+			open:    opts.NewOpenList(),
+			closed:  NewTranspositionTable(),
+			outbox:  make([][]*State, opts.MaxThreads),
+			mailbox: hdaMailbox{signal: make(chan struct{}, 1)},
+		}
+	}
+
+	if board.isBoardEmpty() {
+		return SolveResult{Moves: []uint8{}, Complete: true, Stats: s.stats()}
+	}
+
+	s.pending.Store(1)
+	root := &State{Board: board.Copy()}
+	s.owner(root.Board).mailbox.send([]*State{root})
+
+	stopWatching := context.AfterFunc(ctx, func() {
+		s.cancelled.Store(true)
+		s.stop()
+	})
+	defer stopWatching()
+
+	var wg sync.WaitGroup
+	for _, w := range s.workers {
+		wg.Add(1)
+		go func(w *hdaWorker) {
+			defer wg.Done()
+			w.run()
+		}(w)
+	}
+	wg.Wait()
+
+	return SolveResult{
+		Moves:    s.solution,
+		Complete: !s.cancelled.Load(),
+		Stats:    s.stats(),
+	}
+}
+
+type hdaSearch struct {
+	heuristic Heuristic
+	workers   []*hdaWorker
+
+	// number of states sent but not yet expanded or dropped,
+	// the search space is exhausted when it reaches zero
+	pending atomic.Int64
+
+	// length of the best solution found, states that can't beat it are dropped
+	incumbent atomic.Int32
+	mutex     sync.Mutex
+	solution  []uint8
+
+	done      chan struct{}
+	stopOnce  sync.Once
+	cancelled atomic.Bool
+}
+
+func (s *hdaSearch) stop() {
+	s.stopOnce.Do(func() { close(s.done) })
+}
+
+// finish marks n pending states as handled
+func (s *hdaSearch) finish(n int64) {
+	if s.pending.Add(-n) == 0 {
+		s.stop()
+	}
+}
+
+func (s *hdaSearch) owner(board *Board) *hdaWorker {
+	return s.workers[board.Hash()%uint64(len(s.workers))]
+}
+
+func (s *hdaSearch) foundSolution(moves []uint8) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.solution == nil || len(moves) < len(s.solution) {
+		s.solution = moves
+		s.incumbent.Store(int32(len(moves)))
+	}
+}
+
+// canBeatIncumbent reports whether state could still lead to a shorter solution
+func (s *hdaSearch) canBeatIncumbent(state *State) bool {
+	incumbent := s.incumbent.Load()
+	return state.Path.Len()+1 < int(incumbent) && state.Priority < float32(incumbent)
+}
+
+func (s *hdaSearch) stats() []WorkerStats {
+	stats := make([]WorkerStats, len(s.workers))
+	for i, w := range s.workers {
+		stats[i] = w.stats
+	}
+	return stats
+}
+
+type hdaWorker struct {
+	id      int
+	search  *hdaSearch
+	open    OpenList
+	closed  *TranspositionTable
+	outbox  [][]*State // successors waiting to be sent, per owner
+	mailbox hdaMailbox
+	stats   WorkerStats
+}
+
+func (w *hdaWorker) run() {
+	for {
+		select {
+		case <-w.search.done:
+			return
+		default:
+		}
+
+		for _, state := range w.mailbox.receive() {
+			w.accept(state)
+		}
+
+		if w.open.Len() == 0 {
+			select {
+			case <-w.mailbox.signal:
+				continue
+			case <-w.search.done:
+				return
+			}
+		}
+
+		state := w.open.Pop()
+		// skip states that got a shorter path after they were pushed,
+		// or that can't beat a solution found since
+		if !w.closed.IsStale(state.Board, state.Path.Len()) && w.search.canBeatIncumbent(state) {
+			w.expand(state)
+		}
+		w.search.finish(1)
+	}
+}
+
+// accept puts a state owned by this worker in the open list, unless it has
+// been reached with fewer clicks before
+func (w *hdaWorker) accept(state *State) {
+	w.stats.Received++
+
+	// states that can't beat the incumbent are dropped before they take up
+	// room in the table
+	state.Estimate = w.search.heuristic(state.Board)
+	state.Priority = float32(state.Path.Len()) + state.Estimate
+	if !w.search.canBeatIncumbent(state) {
+		w.search.finish(1)
+		return
+	}
+
+	if !w.closed.Improve(state.Board, state.Path.Len()) {
+		w.stats.Duplicates++
+		w.search.finish(1)
+		return
+	}
+
+	w.open.Push(state)
+	if w.open.Len() > w.stats.MaxOpen {
+		w.stats.MaxOpen = w.open.Len()
+	}
+}
+
+func (w *hdaWorker) expand(state *State) {
+	w.stats.Expanded++
+
+	possibleClicks := state.Board.GetPossibleClicks()
+
+	for _, pos := range possibleClicks {
+		nextBoard := state.Board.Copy()
+		nextBoard.RemoveBricksIterative(pos)
+		nextBoard.Gravity()
+
+		nextPath := state.Path.Then(pos)
+
+		if nextBoard.isBoardEmpty() {
+			w.search.foundSolution(nextPath.Moves())
+			continue
+		}
+
+		owner := w.search.owner(nextBoard)
+		w.outbox[owner.id] = append(w.outbox[owner.id], &State{
+			Board: nextBoard,
+			Path:  nextPath,
+		})
+	}
+
+	// count the successors before the parent is finished, so the number of
+	// pending states can't reach zero while there is still work in flight
+	for id, states := range w.outbox {
+		if len(states) == 0 {
+			continue
+		}
+		w.search.pending.Add(int64(len(states)))
+
+		if id == w.id {
+			for _, next := range states {
+				w.accept(next)
+			}
+		} else {
+			w.search.workers[id].mailbox.send(states)
+			w.stats.Sent += uint64(len(states))
+		}
+		w.outbox[id] = nil
+	}
+}
+
+type hdaMailbox struct {
+	mutex  sync.Mutex
+	states []*State
+	signal chan struct{} // has a value when states were sent since the last receive
+}
+
+func (m *hdaMailbox) send(states []*State) {
+	m.mutex.Lock()
+	m.states = append(m.states, states...)
+	m.mutex.Unlock()
+
+	select {
+	case m.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (m *hdaMailbox) receive() []*State {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	states := m.states
+	m.states = nil
+	return states
+}
@@ -0,0 +1,54 @@
+package formerfast
+
+import (
+	"maps"
+)
+
+// stateMap maps the bricks on a board to a value. Boards of 64 cells or
+// fewer are keyed on State alone, so only bigger boards pay for a key with
+// both words. All boards in a map must have the same size.
+type stateMap[V any] struct {
+	narrow map[[4]uint64]V
+	wide   map[[8]uint64]V
+}
+
+func newStateMap[V any]() *stateMap[V] {
+	return &stateMap[V]{
+		narrow: map[[4]uint64]V{},
+		wide:   map[[8]uint64]V{},
+	}
+}
+
+func wideKey(b *Board) [8]uint64 {
+	key := [8]uint64{}
+	copy(key[:4], b.State[:])
+	copy(key[4:], b.extra.hi[:])
+	return key
+}
+
+func (m *stateMap[V]) get(b *Board) (V, bool) {
+	if b.geometry().small {
+		v, exists := m.narrow[b.State]
+		return v, exists
+	}
+	v, exists := m.wide[wideKey(b)]
+	return v, exists
+}
+
+func (m *stateMap[V]) set(b *Board, v V) {
+	if b.geometry().small {
+		m.narrow[b.State] = v
+	} else {
+		m.wide[wideKey(b)] = v
+	}
+}
+
+func (m *stateMap[V]) len() int {
+	return len(m.narrow) + len(m.wide)
+}
+
+// deleteFunc removes every value del returns true for
+func (m *stateMap[V]) deleteFunc(del func(V) bool) {
+	maps.DeleteFunc(m.narrow, func(_ [4]uint64, v V) bool { return del(v) })
+	maps.DeleteFunc(m.wide, func(_ [8]uint64, v V) bool { return del(v) })
+}
@@ -0,0 +1,43 @@
+package formerfast
+
+// TranspositionTable remembers the fewest clicks used to reach every board
+// state the search has seen. The same board is often reached by clicking
+// the same groups in a different order, and without the table every one of
+// those copies gets pushed and expanded again.
+//
+// States are keyed on the full board state, not on Board.Hash, so two
+// different boards can never be mistaken for each other. The table is not
+// safe for concurrent use: Solve sends every state to the worker its hash
+// belongs to, so each worker has a table of its own.
+type TranspositionTable struct {
+	best *stateMap[uint8]
+}
+
+func NewTranspositionTable() *TranspositionTable {
+	return &TranspositionTable{best: newStateMap[uint8]()}
+}
+
+// Improve records that the state of board can be reached using g clicks. It
+// returns false if the state has already been reached using g clicks or fewer,
+// in which case the new path is no better and should be dropped. If the new
+// path is shorter the state is reopened and Improve returns true.
+func (tt *TranspositionTable) Improve(board *Board, g int) bool {
+	if best, exists := tt.best.get(board); exists && int(best) <= g {
+		return false
+	}
+	tt.best.set(board, uint8(g))
+	return true
+}
+
+// IsStale reports whether a shorter path to the state of board than g clicks
+// has been recorded since it was pushed. Stale states can be skipped when
+// popped, since the shorter path is already waiting in the queue.
+func (tt *TranspositionTable) IsStale(board *Board, g int) bool {
+	best, exists := tt.best.get(board)
+	return exists && int(best) < g
+}
+
+// Len returns the number of distinct states in the table.
+func (tt *TranspositionTable) Len() int {
+	return tt.best.len()
+}
@@ -0,0 +1,42 @@
+package formerfast
+
+import (
+	"fmt"
+)
+
+// VerifyResult tells how far a solution got
+type VerifyResult struct {
+	Played    int    // clicks played before the first bad click, or all of them
+	Remaining int    // bricks left on the board after the clicks that were played
+	Board     *Board // the board after the clicks that were played
+}
+
+// Cleared reports whether the clicks removed every brick
+func (r VerifyResult) Cleared() bool {
+	return r.Remaining == 0
+}
+
+// Verify replays clicks on a copy of board and reports what is left. It
+// returns an error at the first click that is outside the board or on an
+// empty cell, together with the result up to that click. A solution is valid
+// if there is no error and the result is Cleared.
+func Verify(board *Board, clicks []uint8) (VerifyResult, error) {
+	game := NewGame(board)
+
+	var err error
+	for i, pos := range clicks {
+		if _, err = game.Click(pos); err != nil {
+			err = fmt.Errorf("click %d: %w", i, err)
+			break
+		}
+	}
+
+	result := VerifyResult{
+		Played: game.Clicks(),
+		Board:  game.Board(),
+	}
+	for c := orange; c <= blue; c++ {
+		result.Remaining += result.Board.plane(c).onesCount()
+	}
+	return result, err
+}
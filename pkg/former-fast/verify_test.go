@@ -0,0 +1,72 @@
+package formerfast
+
+import (
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	board := testBoards(t)["25-11-2024.json"]
+	start := *board
+	countBricks := func(board *Board) int {
+		count := 0
+		for c := orange; c <= blue; c++ {
+			count += board.plane(c).Count()
+		}
+		return count
+	}
+
+	// click the first possible group until the board is clear
+	solution := []uint8{}
+	for played := board.Copy(); !played.isBoardEmpty(); {
+		pos := played.GetPossibleClicks()[0]
+		played.RemoveBricksIterative(pos)
+		played.Gravity()
+		solution = append(solution, pos)
+	}
+
+	result, err := Verify(board, solution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Cleared() || result.Played != len(solution) {
+		t.Fatalf("solution played %d of %d clicks with %d bricks left", result.Played, len(solution), result.Remaining)
+	}
+	if *board != start {
+		t.Fatal("Verify changed the board it was given")
+	}
+
+	result, err = Verify(board, solution[:3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Cleared() || result.Played != 3 || result.Remaining != countBricks(result.Board) {
+		t.Fatalf("3 clicks: played %d with %d bricks left", result.Played, result.Remaining)
+	}
+
+	// after the first click, the top of its column is empty
+	first := solution[0]
+	x, _ := board.XY(first)
+	for _, test := range []struct {
+		name   string
+		clicks []uint8
+		played int
+	}{
+		{"empty cell", []uint8{first, board.Pos(x, 0)}, 1},
+		{"outside the board", []uint8{63}, 0},
+		{"outside the bitboard", []uint8{first, 200}, 1},
+	} {
+		result, err := Verify(board, test.clicks)
+		if err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+		if result.Played != test.played {
+			t.Errorf("%s: played %d clicks, expected %d", test.name, result.Played, test.played)
+		}
+		if test.played == 0 && *result.Board != *board {
+			t.Errorf("%s: board changed without a click being played", test.name)
+		}
+		if result.Remaining != countBricks(result.Board) {
+			t.Errorf("%s: %d bricks left, but the board has %d", test.name, result.Remaining, countBricks(result.Board))
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package formerfast
+
+import "math/bits"
+
+// zobristKeys has a random key for every color in every cell
+var zobristKeys = func() [4][MaxCells]uint64 {
+	keys := [4][MaxCells]uint64{}
+	seed := uint64(0x9e3779b97f4a7c15)
+	for c := range keys {
+		for pos := range keys[c] {
+			keys[c][pos] = splitMix64(&seed)
+		}
+	}
+	return keys
+}()
+
+// splitMix64 is a small generator, good enough to fill the key table with the
+// same keys on every run
+func splitMix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// zobristOf returns the xor of the keys of color c in every cell of mask
+func zobristOf(c int, mask Plane) uint64 {
+	keys := &zobristKeys[c]
+	h := uint64(0)
+	for word := mask.lo; word != 0; word &= word - 1 {
+		h ^= keys[bits.TrailingZeros64(word)]
+	}
+	for word := mask.hi; word != 0; word &= word - 1 {
+		h ^= keys[64+bits.TrailingZeros64(word)]
+	}
+	return h
+}
+
+// Rehash computes the hash of the board from scratch. The board methods keep
+// the hash up to date, so this is only needed after changing State by hand.
+func (b *Board) Rehash() {
+	b.hash = 0
+	for c := orange; c <= blue; c++ {
+		b.hash ^= zobristOf(c, b.plane(c))
+	}
+}
@@ -8,11 +8,42 @@ import (
 
 type State struct {
 	Board     *Board
-	Moves     []Click // Sequence of moves to reach this state
-	Steps     int     // g: Number of steps taken
-	Estimate  float64 // h: Heuristic value
-	Priority  float64 // f: Steps + Estimate
-	StateHash uint32  // Unique identifier for the board state
+	Path      *MoveNode // Last move made to reach this state, nil for the start
+	Steps     int       // g: Number of steps taken
+	Estimate  float64   // h: Heuristic value
+	Priority  float64   // f: Steps + Estimate
+	StateHash uint64    // Zobrist hash of the board state
+}
+
+// MoveNode is one click in the sequence of moves to reach a state. It points
+// back to the click before it, so states reached from the same state share
+// its moves instead of each copying them.
+type MoveNode struct {
+	Parent *MoveNode
+	Click  Click
+	Depth  int // number of clicks up to and including this one
+}
+
+// Then returns the path made by clicking click after the clicks in n
+func (n *MoveNode) Then(click Click) *MoveNode {
+	return &MoveNode{Parent: n, Click: click, Depth: n.Len() + 1}
+}
+
+// Len returns the number of clicks in the path, a nil path has none
+func (n *MoveNode) Len() int {
+	if n == nil {
+		return 0
+	}
+	return n.Depth
+}
+
+// Moves returns the clicks in the path from first to last
+func (n *MoveNode) Moves() []Click {
+	moves := make([]Click, n.Len())
+	for node := n; node != nil; node = node.Parent {
+		moves[node.Depth-1] = node.Click
+	}
+	return moves
 }
 
 type PriorityQueue []*State
@@ -42,19 +73,24 @@ func (pq *PriorityQueue) Pop() interface{} {
 	return item
 }
 
-var possibleClickCache = map[uint32][]ClickGroup{}
+// possibleClickCache maps the Zobrist hash of a board to its possible clicks
+var possibleClickCache = map[uint64][]ClickGroup{}
 
 // heuristic_tuning: good 6 - 3
 func SolveBoardUsingAStar(board *Board, heuristicTuning float64) []Click {
+	return SolveBoardUsingAStarWith(board, LogHeuristic(heuristicTuning))
+}
+
+// SolveBoardUsingAStarWith is SolveBoardUsingAStar with any heuristic
+func SolveBoardUsingAStarWith(board *Board, estimate Heuristic) []Click {
 	initialHash := board.Hash()
 	pq := &PriorityQueue{}
 
 	initialState := &State{
 		Board:     board.Copy(),
-		Moves:     []Click{},
 		Steps:     0,
-		Estimate:  heuristic(board, heuristicTuning),
-		Priority:  heuristic(board, heuristicTuning),
+		Estimate:  estimate(board),
+		Priority:  estimate(board),
 		StateHash: initialHash,
 	}
 
@@ -68,15 +104,15 @@ func SolveBoardUsingAStar(board *Board, heuristicTuning float64) []Click {
 
 		// Goal check: is the board empty?
 		if isBoardEmpty(current.Board) {
-			return current.Moves
+			return current.Path.Moves()
 		}
 
 		var clickGroups []ClickGroup
-		if newGroup, exists := possibleClickCache[current.Board.Hash()]; exists {
+		if newGroup, exists := possibleClickCache[current.StateHash]; exists {
 			clickGroups = newGroup
 		} else {
 			clickGroups = GetPossibleSectorClicks(current.Board)
-			possibleClickCache[board.Hash()] = clickGroups
+			possibleClickCache[current.StateHash] = clickGroups
 		}
 
 		if i%10000 == 0 {
@@ -90,19 +126,16 @@ func SolveBoardUsingAStar(board *Board, heuristicTuning float64) []Click {
 
 			nextHash := nextBoard.Hash()
 
-			heuristic := heuristic(nextBoard, heuristicTuning)
+			heuristic := estimate(nextBoard)
 
 			// Add cut off huristic to save memmory
 			// if current.Steps+heuristic > current.Priority {
 			// 	continue
 			// }
 
-			nextMoves := append([]Click{}, current.Moves...)
-			nextMoves = append(nextMoves, clickGroup.Click)
-
 			nextState := &State{
 				Board:     nextBoard,
-				Moves:     nextMoves,
+				Path:      current.Path.Then(clickGroup.Click),
 				Steps:     current.Steps + 1,
 				Estimate:  heuristic,
 				Priority:  float64(current.Steps) + 1 + heuristic,
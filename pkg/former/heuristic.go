@@ -0,0 +1,59 @@
+package former
+
+import (
+	"math/bits"
+)
+
+// Heuristic estimates how many clicks are needed to clear the board
+type Heuristic func(board *Board) float64
+
+// LogHeuristic is the natural logarithm of the number of possible clicks,
+// multiplied by heuristicTuning. See heuristic.
+func LogHeuristic(heuristicTuning float64) Heuristic {
+	return func(board *Board) float64 {
+		return heuristic(board, heuristicTuning)
+	}
+}
+
+// LowerBoundHeuristic never overestimates the number of clicks left, it
+// counts how many runs of neighbouring columns each color has
+func LowerBoundHeuristic() Heuristic {
+	return func(board *Board) float64 {
+		return float64(lowerBound(board))
+	}
+}
+
+type WeightedHeuristic struct {
+	Weight    float64
+	Heuristic Heuristic
+}
+
+// CombinedHeuristic adds up the estimates of several heuristics, each
+// multiplied by its weight
+func CombinedHeuristic(terms ...WeightedHeuristic) Heuristic {
+	return func(board *Board) float64 {
+		estimate := 0.0
+		for _, term := range terms {
+			estimate += term.Weight * term.Heuristic(board)
+		}
+		return estimate
+	}
+}
+
+func lowerBound(board *Board) int {
+	columnsByType := map[BrickType]uint64{}
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
+			if brick := board.Bricks[y*board.Width+x]; brick != nil {
+				columnsByType[brick.Type] |= 1 << x
+			}
+		}
+	}
+
+	bound := 0
+	for _, columns := range columnsByType {
+		// count the first column of every run
+		bound += bits.OnesCount64(columns &^ (columns << 1))
+	}
+	return bound
+}
@@ -2,8 +2,6 @@ package former
 
 import (
 	"fmt"
-	"hash/fnv"
-
 	"math/rand"
 )
 
@@ -16,6 +14,15 @@ const (
 
 type BrickType = int
 
+// The brick types under exported names, for code outside the package such as
+// the conversion to formerfast
+const (
+	Green  BrickType = green
+	Blue   BrickType = blue
+	Orange BrickType = orange
+	Pink   BrickType = pink
+)
+
 type Brick struct {
 	Type BrickType
 }
@@ -24,6 +31,8 @@ type Board struct {
 	Height int
 	Width  int
 	Bricks []*Brick
+	hash   uint64 // Zobrist hash of Bricks, see Hash
+	hashed bool   // false until hash has been computed
 }
 
 type Click struct {
@@ -57,7 +66,7 @@ func (b Board) GetBrick(x int, y int) (*Brick, error) {
 	return nil, fmt.Errorf("no brick at pos (%d, %d)", x, y)
 }
 
-func (b Board) RemoveBrick(x int, y int) error {
+func (b *Board) RemoveBrick(x int, y int) error {
 	if x > b.Width || y > b.Height {
 		return fmt.Errorf("coordinates out of bounds")
 	}
@@ -71,12 +80,13 @@ func (b Board) RemoveBrick(x int, y int) error {
 		return fmt.Errorf("coordinates out of bounds")
 	}
 
+	b.toggle(index, b.Bricks[index])
 	b.Bricks[index] = nil
 
 	return nil
 }
 
-func (b Board) Gravity() error {
+func (b *Board) Gravity() error {
 	for x := 0; x < b.Width; x++ {
 		stack := []*Brick{}
 		for y := 0; y < b.Height; y++ {
@@ -88,11 +98,15 @@ func (b Board) Gravity() error {
 
 		for y := b.Height - 1; y >= 0; y-- {
 			index := y*b.Width + x
+			var brick *Brick
 			if len(stack) > 0 {
-				b.Bricks[index] = stack[len(stack)-1]
+				brick = stack[len(stack)-1]
 				stack = stack[:len(stack)-1]
-			} else {
-				b.Bricks[index] = nil
+			}
+			if b.Bricks[index] != brick {
+				b.toggle(index, b.Bricks[index])
+				b.toggle(index, brick)
+				b.Bricks[index] = brick
 			}
 		}
 	}
@@ -152,18 +166,6 @@ func GetPossibleSectorClicks(board *Board) []ClickGroup {
 	return clicks
 }
 
-func (b *Board) Hash() uint32 {
-	h := fnv.New32a()
-	for _, brick := range b.Bricks {
-		var val byte = 0
-		if brick != nil {
-			val = byte(brick.Type)
-		}
-		h.Write([]byte{val})
-	}
-	return h.Sum32()
-}
-
 func (b *Board) RemoveBricksIterative(x, y, brickType int) {
 	stack := []int{x, y}
 	visited := make(map[int]bool)
@@ -184,6 +186,7 @@ func (b *Board) RemoveBricksIterative(x, y, brickType int) {
 
 		visited[index] = true
 
+		b.toggle(index, brick)
 		b.Bricks[index] = nil
 
 		directions := [][2]int{
@@ -237,6 +240,8 @@ func (b *Board) Copy() *Board {
 	newBoard := &Board{
 		Height: b.Height,
 		Width:  b.Width,
+		hash:   b.hash,
+		hashed: b.hashed,
 	}
 	newBoard.Bricks = make([]*Brick, len(b.Bricks))
 	copy(newBoard.Bricks, b.Bricks)
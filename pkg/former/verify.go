@@ -0,0 +1,49 @@
+package former
+
+import (
+	"fmt"
+)
+
+// VerifyResult tells how far a solution got
+type VerifyResult struct {
+	Played    int    // clicks played before the first bad click, or all of them
+	Remaining int    // bricks left on the board after the clicks that were played
+	Board     *Board // the board after the clicks that were played
+}
+
+// Cleared reports whether the clicks removed every brick
+func (r VerifyResult) Cleared() bool {
+	return r.Remaining == 0
+}
+
+// Verify replays clicks on a copy of board and reports what is left. It
+// returns an error at the first click that is outside the board or on an
+// empty cell, together with the result up to that click. A solution is valid
+// if there is no error and the result is Cleared.
+func Verify(board *Board, clicks []Click) (VerifyResult, error) {
+	result := VerifyResult{Board: board.Copy()}
+
+	var err error
+	for i, click := range clicks {
+		if click.X < 0 || click.X >= board.Width || click.Y < 0 || click.Y >= board.Height {
+			err = fmt.Errorf("click %d: (%d, %d) is outside the board", i, click.X, click.Y)
+			break
+		}
+		brick, brickErr := result.Board.GetBrick(click.X, click.Y)
+		if brickErr != nil {
+			err = fmt.Errorf("click %d: %w", i, brickErr)
+			break
+		}
+
+		result.Board.RemoveBricksIterative(click.X, click.Y, brick.Type)
+		result.Board.Gravity()
+		result.Played++
+	}
+
+	for _, brick := range result.Board.Bricks {
+		if brick != nil {
+			result.Remaining++
+		}
+	}
+	return result, err
+}
@@ -0,0 +1,83 @@
+package former
+
+import (
+	"os"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	data, err := os.ReadFile("../../tests/25-11-2024.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	board, err := LoadBoard(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	countBricks := func(board *Board) int {
+		count := 0
+		for _, brick := range board.Bricks {
+			if brick != nil {
+				count++
+			}
+		}
+		return count
+	}
+	bricks := countBricks(board)
+
+	// click the first possible group until the board is clear
+	solution := []Click{}
+	for played := board.Copy(); ; {
+		possible := GetPossibleSectorClicks(played)
+		if len(possible) == 0 {
+			break
+		}
+		click := possible[0]
+		played.RemoveBricksIterative(click.Click.X, click.Click.Y, click.Type)
+		played.Gravity()
+		solution = append(solution, click.Click)
+	}
+
+	result, err := Verify(board, solution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Cleared() || result.Played != len(solution) {
+		t.Fatalf("solution played %d of %d clicks with %d bricks left", result.Played, len(solution), result.Remaining)
+	}
+	if countBricks(board) != bricks {
+		t.Fatal("Verify changed the board it was given")
+	}
+
+	result, err = Verify(board, solution[:3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Cleared() || result.Played != 3 || result.Remaining != countBricks(result.Board) {
+		t.Fatalf("3 clicks: played %d with %d bricks left", result.Played, result.Remaining)
+	}
+
+	// after the first click, the top of its column is empty
+	first := solution[0]
+	for _, test := range []struct {
+		name   string
+		clicks []Click
+		played int
+	}{
+		{"empty cell", []Click{first, {X: first.X, Y: 0}}, 1},
+		{"left of the board", []Click{{X: -1, Y: 0}}, 0},
+		{"right of the board", []Click{first, {X: board.Width, Y: 0}}, 1},
+		{"below the board", []Click{{X: 0, Y: board.Height}}, 0},
+	} {
+		result, err := Verify(board, test.clicks)
+		if err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+		if result.Played != test.played {
+			t.Errorf("%s: played %d clicks, expected %d", test.name, result.Played, test.played)
+		}
+		if result.Remaining != countBricks(result.Board) || (test.played == 0 && result.Remaining != bricks) {
+			t.Errorf("%s: %d bricks left, but the board has %d", test.name, result.Remaining, countBricks(result.Board))
+		}
+	}
+}
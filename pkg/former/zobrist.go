@@ -0,0 +1,41 @@
+package former
+
+// zobristKey returns the random key for a brick of type brickType at index.
+// Boards can have any size, so the keys are made on the fly with splitmix64
+// instead of being looked up in a table.
+func zobristKey(index int, brickType BrickType) uint64 {
+	z := uint64(index*4+brickType+1) * 0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// toggle adds or removes the key of the brick at index from the hash, if the
+// hash has been computed
+func (b *Board) toggle(index int, brick *Brick) {
+	if b.hashed && brick != nil {
+		b.hash ^= zobristKey(index, brick.Type)
+	}
+}
+
+// Hash returns a 64 bit Zobrist hash of the board. It is computed the first
+// time it is needed, and then kept up to date by RemoveBrick,
+// RemoveBricksIterative and Gravity. Call Rehash after changing Bricks by
+// hand.
+func (b *Board) Hash() uint64 {
+	if !b.hashed {
+		b.Rehash()
+	}
+	return b.hash
+}
+
+// Rehash computes the hash of the board from scratch
+func (b *Board) Rehash() {
+	b.hash = 0
+	for index, brick := range b.Bricks {
+		if brick != nil {
+			b.hash ^= zobristKey(index, brick.Type)
+		}
+	}
+	b.hashed = true
+}